@@ -20,4 +20,6 @@ const (
 	AsaasExpired                                           // subscription
 	AsaasDeleted
 	AsaasSuccess
+	AsaasAuthorized       //- Pagamento em cartão autorizado (authorizeOnly), aguardando captura
+	AsaasRefundInProgress //- Estorno em processamento, será estornada após a liquidação
 )
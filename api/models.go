@@ -83,7 +83,9 @@ const (
 		PAYMENT_OVERDUE - Cobrança vencida.
 		PAYMENT_DELETED - Cobrança removida.
 		PAYMENT_RESTORED - Cobrança restaurada.
+		PAYMENT_AUTHORIZED - Pagamento em cartão autorizado, aguardando captura.
 		PAYMENT_REFUNDED - Cobrança estornada.
+		PAYMENT_REFUND_IN_PROGRESS - Estorno em processamento, aguardando liquidação.
 		PAYMENT_RECEIVED_IN_CASH_UNDONE - Recebimento em dinheiro desfeito.
 		PAYMENT_CHARGEBACK_REQUESTED - Recebido chargeback.
 		PAYMENT_CHARGEBACK_DISPUTE - Em disputa de chargeback (caso sejam apresentados documentos para contestação).
@@ -99,6 +101,8 @@ const (
 	PaymentEventOverdue                    PaymentEvent = "PAYMENT_OVERDUE"
 	PaymentEventDeleted                    PaymentEvent = "PAYMENT_DELETED"
 	PaymentEventRestored                   PaymentEvent = "PAYMENT_RESTORED"
+	PaymentEventAuthorized                 PaymentEvent = "PAYMENT_AUTHORIZED"
+	PaymentEventRefundInProgress           PaymentEvent = "PAYMENT_REFUND_IN_PROGRESS"
 	PaymentEventRefunded                   PaymentEvent = "PAYMENT_REFUNDED"
 	PaymentEventReceivedInCashUndone       PaymentEvent = "PAYMENT_RECEIVED_IN_CASH_UNDONE"
 	PaymentEventChargebackRequested        PaymentEvent = "PAYMENT_CHARGEBACK_REQUESTED"
@@ -167,6 +171,7 @@ const (
 	PaymentTypeNone       PaymentType = ""
 	PaymentTypeCreditCard PaymentType = "credit_card"
 	PaymentTypeDebitCard  PaymentType = "debit_card"
+	PaymentTypeVoucher    PaymentType = "voucher"
 	PaymentTypeBoleto     PaymentType = "boleto"
 	PaymentTypePix        PaymentType = "pix"
 	PaymentTypePicPay     PaymentType = "picpay"
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"iter"
+)
+
+// PageFetcher busca a página de índice page (começando em zero) e informa se existem mais páginas
+type PageFetcher[T any] func(ctx context.Context, page int) (items []T, hasMore bool, err error)
+
+type PageOptions struct {
+	// Prefetch quantidade de páginas buscadas em paralelo à frente da página consumida.
+	// Zero busca a próxima página somente quando a atual termina
+	Prefetch int
+}
+
+func NewPageOptions() *PageOptions {
+	return &PageOptions{}
+}
+
+func (this *PageOptions) WithPrefetch(prefetch int) *PageOptions {
+	this.Prefetch = prefetch
+	return this
+}
+
+// GetPrefetch prefetch da primeira opção informada
+func GetPrefetch(opts ...*PageOptions) int {
+	if len(opts) > 0 && opts[0] != nil && opts[0].Prefetch > 0 {
+		return opts[0].Prefetch
+	}
+	return 0
+}
+
+type pageResult[T any] struct {
+	items   []T
+	hasMore bool
+	err     error
+}
+
+// Paginate itera sobre todas as páginas de fetch. O iterador para no primeiro erro,
+// que é entregue com o valor zero de T, ou quando o contexto é cancelado. Páginas
+// buscadas antecipadamente são descartadas quando a iteração termina
+func Paginate[T any](ctx context.Context, fetch PageFetcher[T], opts ...*PageOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+
+		var zero T
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		start := func(page int) chan *pageResult[T] {
+			ch := make(chan *pageResult[T], 1)
+			go func() {
+				items, hasMore, err := fetch(ctx, page)
+				ch <- &pageResult[T]{items: items, hasMore: hasMore, err: err}
+			}()
+			return ch
+		}
+
+		inFlight := GetPrefetch(opts...) + 1
+		pending := []chan *pageResult[T]{}
+		next := 0
+
+		for {
+
+			for len(pending) < inFlight {
+				pending = append(pending, start(next))
+				next++
+			}
+
+			var result *pageResult[T]
+
+			select {
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			case result = <-pending[0]:
+				pending = pending[1:]
+			}
+
+			if result.err != nil {
+				yield(zero, result.err)
+				return
+			}
+
+			for _, it := range result.items {
+				if err := ctx.Err(); err != nil {
+					yield(zero, err)
+					return
+				}
+				if !yield(it, nil) {
+					return
+				}
+			}
+
+			if !result.hasMore || len(result.items) == 0 {
+				return
+			}
+		}
+	}
+}
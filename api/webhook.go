@@ -0,0 +1,36 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// WebhookAuthError is returned when a webhook request can't be authenticated,
+// the request must be rejected and never processed
+type WebhookAuthError struct {
+	Gateway Gateway
+	Reason  string
+}
+
+func NewWebhookAuthError(gateway Gateway, reason string) *WebhookAuthError {
+	return &WebhookAuthError{Gateway: gateway, Reason: reason}
+}
+
+func (this *WebhookAuthError) Error() string {
+	return fmt.Sprintf("%v webhook authentication error: %v", this.Gateway, this.Reason)
+}
+
+func IsWebhookAuthError(err error) bool {
+	var authErr *WebhookAuthError
+	return errors.As(err, &authErr)
+}
+
+// SecureCompare compare a received token with the expected token in constant time.
+// An empty expected token never matches.
+func SecureCompare(expected string, received string) bool {
+	if len(expected) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(received)) == 1
+}
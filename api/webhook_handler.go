@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+const (
+	// default max size of webhook body, 1MB
+	DefaultWebhookMaxBodySize int64 = 1 << 20
+)
+
+// WebhookHandlerError carry the status code to reply to the gateway
+type WebhookHandlerError struct {
+	StatusCode int
+	Err        error
+}
+
+func NewWebhookHandlerError(statusCode int, err error) *WebhookHandlerError {
+	return &WebhookHandlerError{StatusCode: statusCode, Err: err}
+}
+
+func (this *WebhookHandlerError) Error() string {
+	return this.Err.Error()
+}
+
+func (this *WebhookHandlerError) Unwrap() error {
+	return this.Err
+}
+
+// ReadWebhookBody read the request body limited to maxBodySize bytes.
+// Use maxBodySize <= 0 to DefaultWebhookMaxBodySize
+func ReadWebhookBody(w http.ResponseWriter, r *http.Request, maxBodySize int64) ([]byte, error) {
+
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultWebhookMaxBodySize
+	}
+
+	if r.Method != http.MethodPost {
+		return nil, NewWebhookHandlerError(http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, NewWebhookHandlerError(http.StatusRequestEntityTooLarge, err)
+		}
+		return nil, NewWebhookHandlerError(http.StatusBadRequest, err)
+	}
+
+	return body, nil
+}
+
+// WebhookStatusCode get the status code to reply for an error. Authentication
+// errors are 401, WebhookHandlerError use your own status code, any other error is
+// replied with defaultStatusCode.
+func WebhookStatusCode(err error, defaultStatusCode int) int {
+
+	if IsWebhookAuthError(err) {
+		return http.StatusUnauthorized
+	}
+
+	var handlerErr *WebhookHandlerError
+	if errors.As(err, &handlerErr) {
+		return handlerErr.StatusCode
+	}
+
+	return defaultStatusCode
+}
+
+// WriteWebhookError reply the error to gateway. The error detail is not sent, only the status text
+func WriteWebhookError(w http.ResponseWriter, err error, defaultStatusCode int) {
+	statusCode := WebhookStatusCode(err, defaultStatusCode)
+	http.Error(w, http.StatusText(statusCode), statusCode)
+}
+
+// WriteWebhookAck reply 200 to gateway, so the event is not delivered again
+func WriteWebhookAck(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
@@ -2,6 +2,7 @@ package asaas
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,10 +13,12 @@ import (
 	"github.com/mobilemindtec/go-utils/beego/validator"
 	"io"
 	"io/ioutil"
+	"iter"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"reflect"
+	"strconv"
 	_ "time"
 )
 
@@ -97,6 +100,29 @@ func (this *Asaas) CustomerFind(filter map[string]string) (*Response, error) {
 	return this.get(url, resultProcessor)
 }
 
+// CustomerFindIter itera sobre todos os clientes do filtro buscando as páginas sob demanda
+func (this *Asaas) CustomerFindIter(ctx context.Context, filter map[string]string, opts ...*api.PageOptions) iter.Seq2[*Customer, error] {
+
+	offset, _ := strconv.ParseInt(filter["offset"], 10, 64)
+	limit, _ := strconv.ParseInt(filter["limit"], 10, 64)
+
+	return api.Paginate(ctx, asaasPageFetcher(offset, limit, func(offset int64, limit int64) ([]*Customer, bool, error) {
+
+		pageFilter := map[string]string{}
+		for k, v := range filter {
+			pageFilter[k] = v
+		}
+		pageFilter["offset"] = fmt.Sprintf("%v", offset)
+		pageFilter["limit"] = fmt.Sprintf("%v", limit)
+
+		response, err := this.CustomerFind(pageFilter)
+		if err = responseError(response, err); err != nil {
+			return nil, false, err
+		}
+		return response.CustomerResults.Data, response.CustomerResults.HasMore, nil
+	}), opts...)
+}
+
 func (this *Asaas) CustomerGet(id string) (*Response, error) {
 
 	this.Log("Call CustomerGet")
@@ -142,6 +168,44 @@ func (this *Asaas) PaymentCancel(id string) (*Response, error) {
 	return this.delete(fmt.Sprintf("payments/%v", id))
 }
 
+// PaymentUpdate altera valor, vencimento, desconto, multa ou juros de uma cobrança pendente
+func (this *Asaas) PaymentUpdate(payment *PaymentUpdate) (*Response, error) {
+
+	this.Log("Call PaymentUpdate")
+
+	if !this.onValidPaymentUpdate(payment) {
+		return nil, errors.New(this.getMessage("Asaas.ValidationError"))
+	}
+
+	return this.put(payment, fmt.Sprintf("payments/%v", payment.Id), nil)
+}
+
+// PaymentRestore restaura uma cobrança removida
+func (this *Asaas) PaymentRestore(id string) (*Response, error) {
+
+	this.Log("Call PaymentRestore")
+
+	if len(id) == 0 {
+		this.SetValidationError("id", "is required")
+		return nil, errors.New(this.getMessage("Asaas.ValidationError"))
+	}
+
+	return this.post(nil, fmt.Sprintf("payments/%v/restore", id), nil)
+}
+
+// PaymentCapture captura uma cobrança de cartão criada com authorizeOnly
+func (this *Asaas) PaymentCapture(id string) (*Response, error) {
+
+	this.Log("Call PaymentCapture")
+
+	if len(id) == 0 {
+		this.SetValidationError("id", "is required")
+		return nil, errors.New(this.getMessage("Asaas.ValidationError"))
+	}
+
+	return this.post(nil, fmt.Sprintf("payments/%v/captureAuthorizedPayment", id), nil)
+}
+
 // /
 // / É possível estornar cobranças via cartão de crédito recebidas ou confirmadas.
 // / Ao fazer isto o saldo correspondente é debitado de sua conta no Asaas e a cobrança
@@ -149,15 +213,70 @@ func (this *Asaas) PaymentCancel(id string) (*Response, error) {
 // / O cancelamento pode levar até 10 dias úteis para aparecer na fatura de seu cliente.
 // /
 func (this *Asaas) PaymentRefund(id string) (*Response, error) {
+	return this.PaymentRefundWith(NewPaymentRefund(id, 0, ""))
+}
+
+// PaymentRefundPartial estorna parte do valor da cobrança. Os splits são revertidos
+// pelo Asaas e o resultado pode ser consultado em Response.GetSplitReversal
+func (this *Asaas) PaymentRefundPartial(id string, value float64, description string) (*Response, error) {
+	return this.PaymentRefundWith(NewPaymentRefund(id, value, description))
+}
+
+func (this *Asaas) PaymentRefundWith(refund *PaymentRefund) (*Response, error) {
 
 	this.Log("Call PaymentRefund")
 
+	if !this.onValidRefund(refund, "id") {
+		return nil, errors.New(this.getMessage("Asaas.ValidationError"))
+	}
+
+	return this.post(refund, fmt.Sprintf("payments/%v/refund", refund.Id), nil)
+}
+
+// onValidRefund valida o estorno, idKey é o nome do campo de id no erro
+func (this *Asaas) onValidRefund(refund *PaymentRefund, idKey string) bool {
+
+	if refund == nil || len(refund.Id) == 0 {
+		this.SetValidationError(idKey, "is required")
+		return false
+	}
+
+	if refund.Value < 0 {
+		this.SetValidationError("value", "must be bigger than zero")
+		return false
+	}
+
+	for _, it := range refund.SplitRefunds {
+		if len(it.Id) == 0 || it.Value <= 0 {
+			this.SetValidationError("splitRefunds", "split id and value bigger than zero are required")
+			return false
+		}
+	}
+
+	// sem Value o estorno é total e o valor é validado pelo Asaas
+	if refund.Value > 0 && refund.GetSplitRefundsValue() > refund.Value {
+		this.SetValidationError("splitRefunds", "splits refund value can't be bigger than refund value")
+		return false
+	}
+
+	return true
+}
+
+// PaymentRefunds lista os estornos da cobrança
+func (this *Asaas) PaymentRefunds(id string) (*Response, error) {
+
+	this.Log("Call PaymentRefunds")
+
 	if len(id) == 0 {
 		this.SetValidationError("id", "is required")
 		return nil, errors.New(this.getMessage("Asaas.ValidationError"))
 	}
 
-	return this.post(nil, fmt.Sprintf("payments/%v/refund", id), nil)
+	resultProcessor := func(data []byte, response *Response) error {
+		return json.Unmarshal(data, response.RefundResults)
+	}
+
+	return this.get(fmt.Sprintf("payments/%v/refunds", id), resultProcessor)
 }
 
 func (this *Asaas) PaymentReceiveInCash(payment *PaymentInCash) (*Response, error) {
@@ -225,6 +344,26 @@ func (this *Asaas) Payments(filter *DefaultFilter) (*Response, error) {
 	return this.get(url, resultProcessor)
 }
 
+// PaymentsIter itera sobre todas as cobranças do filtro buscando as páginas sob demanda
+func (this *Asaas) PaymentsIter(ctx context.Context, filter *DefaultFilter, opts ...*api.PageOptions) iter.Seq2[*Response, error] {
+
+	if filter == nil {
+		filter = NewDefaultFilter()
+	}
+
+	return api.Paginate(ctx, asaasPageFetcher(filter.Offset, filter.Limit, func(offset int64, limit int64) ([]*Response, bool, error) {
+
+		pageFilter := *filter
+		pageFilter.Offset, pageFilter.Limit = offset, limit
+
+		response, err := this.Payments(&pageFilter)
+		if err = responseError(response, err); err != nil {
+			return nil, false, err
+		}
+		return response.PaymentResults.Data, response.PaymentResults.HasMore, nil
+	}), opts...)
+}
+
 func (this *Asaas) PaymentGetPixQrCode(id string) (*Response, error) {
 
 	this.Log("Call PaymentGetPixQrCode")
@@ -296,15 +435,20 @@ func (this *Asaas) InstallmentCancel(installmentId string) (*Response, error) {
 // / O cancelamento pode levar até 10 dias úteis para aparecer na fatura de seu cliente.
 // /
 func (this *Asaas) InstallmentRefund(installmentId string) (*Response, error) {
+	return this.InstallmentRefundWith(NewPaymentRefund(installmentId, 0, ""))
+}
+
+// InstallmentRefundWith estorna o parcelamento com valor e descrição, refund.Id é o
+// id do parcelamento. Com Value zero o estorno é do valor total
+func (this *Asaas) InstallmentRefundWith(refund *PaymentRefund) (*Response, error) {
 
 	this.Log("Call InstallmentRefund")
 
-	if len(installmentId) == 0 {
-		this.SetValidationError("installmentId", "is required")
+	if !this.onValidRefund(refund, "installmentId") {
 		return nil, errors.New(this.getMessage("Asaas.ValidationError"))
 	}
 
-	return this.post(nil, fmt.Sprintf("installments/%v/refund", installmentId), nil)
+	return this.post(refund, fmt.Sprintf("installments/%v/refund", refund.Id), nil)
 }
 
 func (this *Asaas) TokenCreate(tokenRequest *TokenRequest) (*Response, error) {
@@ -557,6 +701,26 @@ func (this *Asaas) FinancialTransactionsList(filter *DefaultFilter) (*Response,
 	return this.get(url, resultProcessor)
 }
 
+// FinancialTransactionsListIter itera sobre todo o extrato do filtro buscando as páginas sob demanda
+func (this *Asaas) FinancialTransactionsListIter(ctx context.Context, filter *DefaultFilter, opts ...*api.PageOptions) iter.Seq2[*FinancialTransaction, error] {
+
+	if filter == nil {
+		filter = NewDefaultFilter()
+	}
+
+	return api.Paginate(ctx, asaasPageFetcher(filter.Offset, filter.Limit, func(offset int64, limit int64) ([]*FinancialTransaction, bool, error) {
+
+		pageFilter := *filter
+		pageFilter.Offset, pageFilter.Limit = offset, limit
+
+		response, err := this.FinancialTransactionsList(&pageFilter)
+		if err = responseError(response, err); err != nil {
+			return nil, false, err
+		}
+		return response.FinancialTransactionResults.Data, response.FinancialTransactionResults.HasMore, nil
+	}), opts...)
+}
+
 func (this *Asaas) CurrentBalance() (*Response, error) {
 	this.Log("Call CurrentBalance")
 	return this.get("finance/getCurrentBalance", nil)
@@ -608,6 +772,26 @@ func (this *Asaas) TransferList(filter *DefaultFilter) (*Response, error) {
 	return this.get(url, resultProcessor)
 }
 
+// TransferListIter itera sobre todas as transferências do filtro buscando as páginas sob demanda
+func (this *Asaas) TransferListIter(ctx context.Context, filter *DefaultFilter, opts ...*api.PageOptions) iter.Seq2[*TransferResult, error] {
+
+	if filter == nil {
+		filter = NewDefaultFilter()
+	}
+
+	return api.Paginate(ctx, asaasPageFetcher(filter.Offset, filter.Limit, func(offset int64, limit int64) ([]*TransferResult, bool, error) {
+
+		pageFilter := *filter
+		pageFilter.Offset, pageFilter.Limit = offset, limit
+
+		response, err := this.TransferList(&pageFilter)
+		if err = responseError(response, err); err != nil {
+			return nil, false, err
+		}
+		return response.TransferResults.Data, response.TransferResults.HasMore, nil
+	}), opts...)
+}
+
 func (this *Asaas) AccountCreate(account *Account) (*Response, error) {
 	this.Log("Call AccountCreate")
 
@@ -1307,6 +1491,51 @@ func (this *Asaas) onValidPayment(payment *Payment) bool {
 	return true
 }
 
+func (this *Asaas) onValidPaymentUpdate(payment *PaymentUpdate) bool {
+
+	if payment == nil {
+		this.SetValidationError("payment", "is required")
+		return false
+	}
+
+	this.EntityValidatorResult, _ = this.EntityValidator.Valid(payment, func(validator *validation.Validation) {
+
+		if payment.Value < 0 {
+			validator.SetError("Value", "Value must be bigger than zero")
+		}
+
+		if payment.Discount != nil && payment.Discount.Value < 0 {
+			validator.SetError("Discount", "Discount value must be bigger than zero")
+		}
+
+		if payment.Discount != nil && payment.Discount.Type == DiscountFixed && payment.Value > 0 &&
+			payment.Discount.Value >= payment.Value {
+			validator.SetError("Discount", "Discount must be less than payment value")
+		}
+
+		if payment.Fine != nil && payment.Fine.Value < 0 {
+			validator.SetError("Fine", "Fine value must be bigger than zero")
+		}
+
+		if payment.Interest != nil && payment.Interest.Value < 0 {
+			validator.SetError("Interest", "Interest value must be bigger than zero")
+		}
+
+		for i, it := range payment.Splits {
+			if it.FixedValue <= 0 && it.PercentualValue <= 0 {
+				validator.SetError(fmt.Sprintf("Split.%v", i), "Set fixed valur or percentual value")
+			}
+		}
+	})
+
+	if this.EntityValidatorResult.HasError {
+		this.onValidationErrors()
+		return false
+	}
+
+	return true
+}
+
 func (this *Asaas) getMessage(key string, args ...interface{}) string {
 	return i18n.Tr(this.Lang, key, args)
 }
@@ -1346,3 +1575,30 @@ func (this *Asaas) urlQuery(filter map[string]string) string {
 	return url
 }
 
+const iterPageLimit = 100
+
+// asaasPageFetcher adapta uma listagem com offset e limit para api.Paginate
+func asaasPageFetcher[T any](offset int64, limit int64, list func(offset int64, limit int64) ([]T, bool, error)) api.PageFetcher[T] {
+
+	if limit <= 0 {
+		limit = iterPageLimit
+	}
+
+	return func(ctx context.Context, index int) ([]T, bool, error) {
+		return list(offset+int64(index)*limit, limit)
+	}
+}
+
+// responseError erro da requisição ou erro de validação retornado pela api
+func responseError(response *Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if response == nil {
+		return errors.New("Asaas empty response")
+	}
+	if response.Error {
+		return errors.New(response.Message)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/mobilemindtec/go-payments/api"
 	"io"
+	"math"
 	"time"
 )
 
@@ -439,6 +440,110 @@ type Split struct {
 	PercentualValue float64 `json:"percentualValue"`
 }
 
+type SplitStatus string
+
+const (
+	SplitPending        SplitStatus = "PENDING"
+	SplitAwaitingCredit SplitStatus = "AWAITING_CREDIT"
+	SplitCancelled      SplitStatus = "CANCELLED"
+	SplitDone           SplitStatus = "DONE"
+	SplitRefunded       SplitStatus = "REFUNDED"
+	SplitRefused        SplitStatus = "REFUSED"
+)
+
+// SplitResult split retornado na cobrança
+type SplitResult struct {
+	Id                 string      `json:"id"`
+	WalletId           string      `json:"walletId"`
+	FixedValue         float64     `json:"fixedValue"`
+	PercentualValue    float64     `json:"percentualValue"`
+	TotalValue         float64     `json:"totalValue"` // valor calculado do split
+	Status             SplitStatus `json:"status"`
+	CancellationReason string      `json:"cancellationReason"`
+}
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "PENDING"
+	RefundDone      RefundStatus = "DONE"
+	RefundCancelled RefundStatus = "CANCELLED"
+)
+
+// SplitRefund valor a ser revertido de um split no estorno
+type SplitRefund struct {
+	Id    string  `json:"id"` // id do split da cobrança, SplitResult.Id
+	Value float64 `json:"value"`
+}
+
+// PaymentRefund estorno total ou parcial, com Value zero o estorno é do valor total.
+// Sem SplitRefunds o Asaas define quanto é revertido de cada split
+type PaymentRefund struct {
+	Id           string         `json:"-" valid:"Required"`
+	Value        float64        `json:"value,omitempty"`
+	Description  string         `json:"description,omitempty"`
+	SplitRefunds []*SplitRefund `json:"splitRefunds,omitempty"`
+}
+
+func NewPaymentRefund(id string, value float64, description string) *PaymentRefund {
+	return &PaymentRefund{Id: id, Value: value, Description: description}
+}
+
+func (this *PaymentRefund) AddSplitRefund(splitId string, value float64) *PaymentRefund {
+	this.SplitRefunds = append(this.SplitRefunds, &SplitRefund{Id: splitId, Value: value})
+	return this
+}
+
+// GetSplitRefundsValue soma dos valores revertidos dos splits
+func (this *PaymentRefund) GetSplitRefundsValue() float64 {
+	var value float64
+	for _, it := range this.SplitRefunds {
+		value += it.Value
+	}
+	return math.Round(value*100) / 100
+}
+
+// RefundedSplit valor de um split revertido pelo estorno
+type RefundedSplit struct {
+	Id    string  `json:"id"`
+	Value float64 `json:"value"`
+	Done  bool    `json:"done"`
+}
+
+type Refund struct {
+	DateCreated           string           `json:"dateCreated"`
+	Status                RefundStatus     `json:"status"`
+	Value                 float64          `json:"value"`
+	Description           string           `json:"description"`
+	EffectiveDate         string           `json:"effectiveDate"`
+	TransactionReceiptUrl string           `json:"transactionReceiptUrl"`
+	RefundedSplits        []*RefundedSplit `json:"refundedSplits"`
+}
+
+func (this *Refund) IsCancelled() bool {
+	return this.Status == RefundCancelled
+}
+
+type RefundResults struct {
+	Object     string    `json:"object"`
+	HasMore    bool      `json:"hasMore"`
+	TotalCount int64     `json:"totalCount"`
+	Limit      int64     `json:"limit"`
+	Offset     int64     `json:"offset"`
+	Data       []*Refund `json:"data"`
+}
+
+// SplitReversal quanto de cada carteira foi revertido pelos estornos da cobrança
+type SplitReversal struct {
+	SplitId        string
+	WalletId       string
+	Status         SplitStatus
+	Value          float64 // valor original do split
+	RefundedValue  float64 // soma revertida nos estornos não cancelados
+	RemainingValue float64
+	Done           bool // todas as reversões já efetivadas
+}
+
 /*
 Token
 
@@ -462,13 +567,14 @@ type Payment struct {
 	TotalValue        float64         `json:"totalValue,omitempty"`        // valor total para parcelamento
 	Discount          *Discount       `json:"discount,omitempty"`
 	Interest          *Interest       `json:"interest,omitempty"`
-	Fine              *Fine           `json:"file,omitempty"`
+	Fine              *Fine           `json:"fine,omitempty"`
 	PostalService     bool            `json:"postalService,omitempty"`
 	Customer          string          `json:"customer"`
 	Card              *Card           `json:"creditCard,omitempty"`           // obrigatório compra cartão
 	CardHolderInfo    *CardHolderInfo `json:"creditCardHolderInfo,omitempty"` // obrigatório compra cartão
 	CardToken         string          `json:"creditCardToken,omitempty"`      // obrigatório compra cartão
 	RemoteIp          string          `json:"remoteIp,omitempty"`             // obrigatório compra cartão
+	AuthorizeOnly     bool            `json:"authorizeOnly,omitempty"`        // somente autoriza o cartão, capturar com PaymentCapture
 	Splits            []*Split        `json:"split,omitempty"`
 
 	PaymentType PaymentType `json:"-"`
@@ -499,6 +605,29 @@ func (this *Payment) SetDueDate(date time.Time) {
 	this.DueDate = DateFormat(date)
 }
 
+// PaymentUpdate alteração de cobrança existente, campos vazios não são alterados
+type PaymentUpdate struct {
+	Id                string      `json:"-" valid:"Required"`
+	BillingType       BillingType `json:"billingType,omitempty"`
+	Value             float64     `json:"value,omitempty"`
+	DueDate           string      `json:"dueDate,omitempty"`
+	Description       string      `json:"description,omitempty"`
+	ExternalReference string      `json:"externalReference,omitempty"`
+	Discount          *Discount   `json:"discount,omitempty"`
+	Interest          *Interest   `json:"interest,omitempty"`
+	Fine              *Fine       `json:"fine,omitempty"`
+	Splits            []*Split    `json:"split,omitempty"`
+}
+
+func NewPaymentUpdate(id string) *PaymentUpdate {
+	return &PaymentUpdate{Id: id}
+}
+
+func (this *PaymentUpdate) SetDueDate(date time.Time) *PaymentUpdate {
+	this.DueDate = DateFormat(date)
+	return this
+}
+
 func (this *Payment) SetEndDate(date time.Time) {
 	this.EndDate = DateFormat(date)
 }
@@ -919,8 +1048,12 @@ type Response struct {
 
 	Card *CardResponse `json:"creditCard"`
 
+	Refunds []*Refund      `json:"refunds"`
+	Splits  []*SplitResult `json:"split"`
+
 	CustomerResults             *CustomerResults
 	PaymentResults              *PaymentResults
+	RefundResults               *RefundResults
 	FinancialTransactionResults *FinancialTransactionResults
 	BankAccount                 *BankAccount `json:"bankAccount,omitempty"`
 	TransferResults             *TransferResults
@@ -963,6 +1096,7 @@ func NewResponse() *Response {
 	return &Response{
 		CustomerResults:             &CustomerResults{Data: []*Customer{}},
 		PaymentResults:              &PaymentResults{Data: []*Response{}},
+		RefundResults:               &RefundResults{Data: []*Refund{}},
 		FinancialTransactionResults: &FinancialTransactionResults{Data: []*FinancialTransaction{}},
 		TransferResults:             &TransferResults{Data: []*TransferResult{}},
 		AccountResults:              &AccountResults{Data: []*Account{}},
@@ -1005,6 +1139,9 @@ func (this *Response) BuildStatus() {
 	case "REFUND_REQUESTED":
 		this.Status = api.AsaasRefundRequested
 		break
+	case "REFUND_IN_PROGRESS":
+		this.Status = api.AsaasRefundInProgress
+		break
 	case "CHARGEBACK_REQUESTED":
 		this.Status = api.AsaasChargebackRequested
 		break
@@ -1029,7 +1166,71 @@ func (this *Response) BuildStatus() {
 	case "EXPIRED":
 		this.Status = api.AsaasExpired
 		break
+	case "AUTHORIZED":
+		this.Status = api.AsaasAuthorized
+		break
+	}
+}
+
+// IsRefundInProgress estorno solicitado ou aguardando a liquidação para ser efetivado
+func (this *Response) IsRefundInProgress() bool {
+	return this.StatusText == "REFUND_IN_PROGRESS" || this.StatusText == "REFUND_REQUESTED"
+}
+
+// GetRefundedValue soma dos estornos não cancelados
+func (this *Response) GetRefundedValue() float64 {
+	var value float64
+	for _, it := range this.Refunds {
+		if !it.IsCancelled() {
+			value += it.Value
+		}
 	}
+	return value
+}
+
+// GetSplitReversal relatório de reversão dos splits pelos estornos da cobrança
+func (this *Response) GetSplitReversal() []*SplitReversal {
+
+	reversals := []*SplitReversal{}
+	byId := map[string]*SplitReversal{}
+
+	for _, it := range this.Splits {
+		reversal := &SplitReversal{
+			SplitId:  it.Id,
+			WalletId: it.WalletId,
+			Status:   it.Status,
+			Value:    it.TotalValue,
+			Done:     true,
+		}
+		byId[it.Id] = reversal
+		reversals = append(reversals, reversal)
+	}
+
+	for _, refund := range this.Refunds {
+		if refund.IsCancelled() {
+			continue
+		}
+		for _, it := range refund.RefundedSplits {
+			reversal, ok := byId[it.Id]
+			if !ok {
+				// split não retornado na cobrança
+				reversal = &SplitReversal{SplitId: it.Id, Done: true}
+				byId[it.Id] = reversal
+				reversals = append(reversals, reversal)
+			}
+			reversal.RefundedValue += it.Value
+			reversal.Done = reversal.Done && it.Done
+		}
+	}
+
+	for _, it := range reversals {
+		it.RemainingValue = math.Round((it.Value-it.RefundedValue)*100) / 100
+		if it.RemainingValue < 0 {
+			it.RemainingValue = 0
+		}
+	}
+
+	return reversals
 }
 
 func (this *Response) GetPayZenSOAPStatus() api.TransactionStatus {
@@ -1048,7 +1249,7 @@ func (this *Response) GetPayZenSOAPStatus() api.TransactionStatus {
 		return api.Refunded
 	case api.AsaasReceivedInCash:
 		return api.Captured
-	case api.AsaasRefundRequested:
+	case api.AsaasRefundRequested, api.AsaasRefundInProgress:
 		return api.PendingRefund
 	case api.AsaasChargebackRequested:
 		return api.Chargeback
@@ -1070,6 +1271,8 @@ func (this *Response) GetPayZenSOAPStatus() api.TransactionStatus {
 		return api.Canceled
 	case api.AsaasSuccess:
 		return api.Success
+	case api.AsaasAuthorized:
+		return api.Authorised
 	default:
 		return api.Error
 	}
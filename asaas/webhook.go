@@ -6,6 +6,13 @@ import (
 	"fmt"
 	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/beego/validator"
+	"net"
+	"strings"
+)
+
+const (
+	// header enviado pelo Asaas com o authToken configurado no WebhookObject
+	WebhookAccessTokenHeader = "asaas-access-token"
 )
 
 type EventType string
@@ -120,11 +127,26 @@ func NewWebhookData() *WebhookData {
 	return &WebhookData{}
 }
 
+// DedupKey identify the event on retries, Asaas send the same event, payment and status
+func (this *WebhookData) DedupKey() string {
+	if this.Response == nil {
+		return fmt.Sprintf("asaas:%v", this.Event)
+	}
+	return fmt.Sprintf("asaas:%v:%v:%v", this.Event, this.Response.Id, this.Response.StatusText)
+}
+
 type Webhook struct {
 	Debug              bool
 	EntityValidator    *validator.EntityValidator
 	ValidationErrors   map[string]string
 	HasValidationError bool
+
+	// token da conta principal
+	AuthToken string
+	// tokens por conta ou subconta, chave é o id da conta
+	AccountTokens map[string]string
+	// quando vazio qualquer ip é aceito
+	AllowedIPs []*net.IPNet
 }
 
 func NewWebhook(lang string) *Webhook {
@@ -141,6 +163,88 @@ func (this *Webhook) SetDebug() {
 	this.Debug = true
 }
 
+func (this *Webhook) SetAuthToken(token string) *Webhook {
+	this.AuthToken = token
+	return this
+}
+
+func (this *Webhook) AddAccountToken(accountId string, token string) *Webhook {
+	if this.AccountTokens == nil {
+		this.AccountTokens = map[string]string{}
+	}
+	this.AccountTokens[accountId] = token
+	return this
+}
+
+// AddAllowedIP accept a single ip or a CIDR block
+func (this *Webhook) AddAllowedIP(ips ...string) error {
+	for _, ip := range ips {
+		if !strings.Contains(ip, "/") {
+			if strings.Contains(ip, ":") {
+				ip = fmt.Sprintf("%v/128", ip)
+			} else {
+				ip = fmt.Sprintf("%v/32", ip)
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(ip)
+		if err != nil {
+			return fmt.Errorf("invalid allowed ip %v: %v", ip, err)
+		}
+		this.AllowedIPs = append(this.AllowedIPs, ipNet)
+	}
+	return nil
+}
+
+// Verify check the asaas-access-token header against the token of account. When
+// accountId is empty the main account token is used. remoteAddr can be an ip or host:port.
+func (this *Webhook) Verify(accountId string, token string, remoteAddr string) error {
+
+	expected := this.AuthToken
+
+	if len(accountId) > 0 {
+		accountToken, ok := this.AccountTokens[accountId]
+		if !ok {
+			return api.NewWebhookAuthError(api.GatewayAsaas, fmt.Sprintf("unknown account %v", accountId))
+		}
+		expected = accountToken
+	}
+
+	if !api.SecureCompare(expected, token) {
+		return api.NewWebhookAuthError(api.GatewayAsaas, fmt.Sprintf("invalid %v", WebhookAccessTokenHeader))
+	}
+
+	if len(this.AllowedIPs) > 0 && !this.isAllowedIP(remoteAddr) {
+		return api.NewWebhookAuthError(api.GatewayAsaas, fmt.Sprintf("ip %v not allowed", remoteAddr))
+	}
+
+	return nil
+}
+
+// ParseAndVerify verify the request and parse the body only when authenticated
+func (this *Webhook) ParseAndVerify(accountId string, token string, remoteAddr string, body []byte) (*WebhookData, error) {
+	if err := this.Verify(accountId, token, remoteAddr); err != nil {
+		return nil, err
+	}
+	return this.Parse(body)
+}
+
+func (this *Webhook) isAllowedIP(remoteAddr string) bool {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(strings.TrimSpace(host))
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range this.AllowedIPs {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func (this *Webhook) Parse(body []byte) (*WebhookData, error) {
 	data := NewWebhookData()
 
@@ -0,0 +1,68 @@
+package asaas
+
+import (
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	"net/http"
+)
+
+type WebhookCallback func(r *http.Request, data *WebhookData) error
+
+// WebhookHandler is a http.Handler to Asaas webhooks. On beego, register
+// with web.Handler(path, handler) or call ServeHTTP(ctx.ResponseWriter, ctx.Request).
+//
+// Asaas expects 200 for every delivered event, any other status is retried and
+// after some failures the webhook queue is interrupted. Authentication errors
+// are replied with 401, invalid body with 400 and callback errors with 500.
+type WebhookHandler struct {
+	Webhook     *Webhook
+	Callback    WebhookCallback
+	MaxBodySize int64
+	// resolve the account id of request, eg. from url path or query.
+	// when nil, the main account token is used
+	AccountResolver func(r *http.Request) string
+}
+
+func NewWebhookHandler(webhook *Webhook, callback WebhookCallback) *WebhookHandler {
+	return &WebhookHandler{Webhook: webhook, Callback: callback, MaxBodySize: api.DefaultWebhookMaxBodySize}
+}
+
+func (this *WebhookHandler) WithAccountResolver(resolver func(r *http.Request) string) *WebhookHandler {
+	this.AccountResolver = resolver
+	return this
+}
+
+func (this *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, err := api.ReadWebhookBody(w, r, this.MaxBodySize)
+
+	if err != nil {
+		api.WriteWebhookError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	accountId := ""
+	if this.AccountResolver != nil {
+		accountId = this.AccountResolver(r)
+	}
+
+	// copy per request, validation state is kept on webhook
+	webhook := *this.Webhook
+
+	data, err := webhook.ParseAndVerify(accountId, r.Header.Get(WebhookAccessTokenHeader), r.RemoteAddr, body)
+
+	if err != nil {
+		if webhook.Debug {
+			fmt.Println("**** Asaas.WebhookHandler: ", err)
+		}
+		api.WriteWebhookError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := this.Callback(r, data); err != nil {
+		api.WriteWebhookError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	api.WriteWebhookAck(w)
+}
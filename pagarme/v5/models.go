@@ -1,11 +1,13 @@
 package v5
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/beego/beego/v2/core/logs"
 	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/v2/optional"
 	"log"
+	"sort"
 	"strings"
 	"time"
 )
@@ -23,6 +25,13 @@ type CustomerType string
 type DocumentType string
 type Gender string
 type PaymentMethod string
+
+// OrderPaymentProgress situação do pagamento de pedidos com múltiplos pagamentos
+type OrderPaymentProgress string
+
+type AuthenticationType string
+
+type ThreeDSecureMpi string
 type BankCode string
 type BoletoType string
 type CardType string
@@ -34,7 +43,11 @@ type SchemeType string
 type OperationType string
 type InvoiceStatus string
 type ChargeStatus string
+type OrderStatus string
+type CheckoutStatus string
 type TransferInterval string
+type AnticipationStatus string
+type AnticipationTimeframe string
 type BankAccountType string
 
 type WebhookEvent string
@@ -89,8 +102,20 @@ const (
 )
 const (
 	MethodCreditCard PaymentMethod = "credit_card"
+	MethodDebitCard  PaymentMethod = "debit_card"
+	MethodVoucher    PaymentMethod = "voucher" // vale refeição/alimentação
 	MethodBoleto     PaymentMethod = "boleto"
 	MethodPix        PaymentMethod = "pix"
+	MethodCheckout   PaymentMethod = "checkout" // página de pagamento hospedada
+)
+
+const (
+	AuthenticationThreeDSecure AuthenticationType = "threed_secure"
+)
+
+const (
+	MpiAcquirer   ThreeDSecureMpi = "acquirer"    // autenticação feita pela adquirente, redireciona para SuccessUrl
+	MpiThirdParty ThreeDSecureMpi = "third_party" // autenticação feita por MPI externo, envia Cavv/Eci
 )
 const (
 	AuthAndCapture OperationType = "auth_and_capture"
@@ -135,6 +160,26 @@ const (
 	InvoiceFailed    InvoiceStatus = "failed"
 )
 
+const (
+	OrderPending  OrderStatus = "pending"
+	OrderPaid     OrderStatus = "paid"
+	OrderCanceled OrderStatus = "canceled"
+	OrderFailed   OrderStatus = "failed"
+)
+
+const (
+	OrderUnpaid        OrderPaymentProgress = "unpaid"
+	OrderPartiallyPaid OrderPaymentProgress = "partially_paid"
+	OrderFullyPaid     OrderPaymentProgress = "fully_paid"
+)
+
+const (
+	CheckoutOpen     CheckoutStatus = "open"
+	CheckoutCanceled CheckoutStatus = "canceled"
+	CheckoutClosed   CheckoutStatus = "closed"
+	CheckoutExpired  CheckoutStatus = "expired"
+)
+
 const (
 	ChargePending    ChargeStatus = "pending"
 	ChargePaid       ChargeStatus = "paid"
@@ -151,6 +196,19 @@ const (
 	Monthly TransferInterval = "monthly"
 )
 
+const (
+	AnticipationBuilding AnticipationStatus = "building"
+	AnticipationPending  AnticipationStatus = "pending"
+	AnticipationApproved AnticipationStatus = "approved"
+	AnticipationRefused  AnticipationStatus = "refused"
+	AnticipationCanceled AnticipationStatus = "canceled" // cancelada pela Pagar.me ou pela dashboard, a API v5 não cancela
+)
+
+const (
+	TimeframeStart AnticipationTimeframe = "start" // recebíveis mais próximos do vencimento original
+	TimeframeEnd   AnticipationTimeframe = "end"   // recebíveis mais distantes, taxa maior
+)
+
 const (
 	Checking BankAccountType = "checking" // corrente
 	Savings  BankAccountType = "savings"  // poupança
@@ -246,6 +304,72 @@ type WebhookObject[T any] struct {
 	Data      T            `json:"data"`
 }
 
+// Hook entrega de webhook registrada na Pagar.me
+type Hook struct {
+	Id             string          `json:"id"`
+	Url            string          `json:"url"`
+	Event          WebhookEvent    `json:"event"`
+	Status         WebhookStatus   `json:"status"`
+	Attempts       int64           `json:"attempts"`
+	LastAttempt    string          `json:"last_attempt"`
+	ResponseStatus string          `json:"response_status"`
+	ResponseRaw    string          `json:"response_raw"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+	Account        *Account        `json:"account"`
+	Data           json.RawMessage `json:"data"`
+}
+
+type hookDataRef struct {
+	Id    string `json:"id"`
+	Order *struct {
+		Id string `json:"id"`
+	} `json:"order"`
+}
+
+func (this *Hook) IsFailed() bool {
+	return this.Status == WebhookFailed
+}
+
+// GetObjectId id do objeto do evento (pedido, cobrança, etc.)
+func (this *Hook) GetObjectId() string {
+	ref := new(hookDataRef)
+	if len(this.Data) > 0 && json.Unmarshal(this.Data, ref) == nil {
+		return ref.Id
+	}
+	return ""
+}
+
+// GetOrderId id do pedido relacionado ao evento, para eventos order.* e charge.*
+func (this *Hook) GetOrderId() string {
+	ref := new(hookDataRef)
+	if len(this.Data) == 0 || json.Unmarshal(this.Data, ref) != nil {
+		return ""
+	}
+	if ref.Order != nil {
+		return ref.Order.Id
+	}
+	if strings.HasPrefix(string(this.Event), "order.") {
+		return ref.Id
+	}
+	return ""
+}
+
+type HookPtr = *Hook
+type Hooks = []HookPtr
+
+// HookSweep resultado do reenvio de hooks com falha
+type HookSweep struct {
+	Retried Hooks
+	Errors  map[string]*ErrorResponse
+}
+
+func (this *HookSweep) HasErrors() bool {
+	return len(this.Errors) > 0
+}
+
+type HookSweepPtr = *HookSweep
+
 type Order struct {
 	Code             string       `json:"code" valid:"Required;MaxSize(64)"`
 	Customer         *Customer    `json:"customer,omitempty"`
@@ -257,58 +381,233 @@ type Order struct {
 	SessionId        string       `json:"session_id"`
 	AntifraudEnabled bool         `json:"antifraud_enabled"`
 
+	openRequested bool // pedido criado com NewOpenOrder
+
 	Id     string `json:"id,omitempty"`
 	Amount int64  `json:"amount,omitempty"`
 
-	Status    string      `json:"status,omitempty"`
+	Status    OrderStatus `json:"status,omitempty"`
 	CreatedAt string      `json:"created_at,omitempty"`
 	UpdatedAt string      `json:"updated_at,omitempty"`
+	ClosedAt  string      `json:"closed_at,omitempty"`
 	Charges   []*Charge   `json:"charges,omitempty"`
 	Checkouts []*Checkout `json:"checkouts,omitempty"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	Shipping  *Shipping   `json:"shipping,omitempty"`
+}
+
+// GetCheckoutUrl url da página de pagamento do checkout aberto
+func (this *Order) GetCheckoutUrl() string {
+	for _, it := range this.Checkouts {
+		if it.Status == CheckoutOpen {
+			return it.PaymentUrl
+		}
+	}
+	return ""
+}
+
+// SetBillingAddressId usa um endereço salvo do cliente como endereço de cobrança dos cartões
+func (this *Order) SetBillingAddressId(addressId string) *Order {
+	for _, it := range this.Payments {
+		if card := it.GetCard(); card != nil {
+			card.BillingAddressId = addressId
+			card.BillingAddress = nil
+		}
+	}
+	return this
 }
 
 func (this *Order) IsCreditCard() bool {
-	if len(this.Payments) > 0 {
-		return this.Payments[0].IsCreditCard()
+	for _, it := range this.Payments {
+		if it.IsCreditCard() {
+			return true
+		}
 	}
 	return false
 }
 
+// IsCard algum pagamento com cartão de crédito, débito ou voucher
+func (this *Order) IsCard() bool {
+	return len(this.GetCardPayments()) > 0
+}
+
+// IsMultiPayment pedido pago com mais de um pagamento, ex.: dois cartões ou cartão e pix
+func (this *Order) IsMultiPayment() bool {
+	return len(this.Payments) > 1
+}
+
+// GetCardPayments pagamentos com cartão, na ordem do pedido
+func (this *Order) GetCardPayments() []*Payment {
+	payments := []*Payment{}
+	for _, it := range this.Payments {
+		if it.IsCard() {
+			payments = append(payments, it)
+		}
+	}
+	return payments
+}
+
+func (this *Order) GetPaymentsByMethod(method PaymentMethod) []*Payment {
+	payments := []*Payment{}
+	for _, it := range this.Payments {
+		if it.PaymentMethod == method {
+			payments = append(payments, it)
+		}
+	}
+	return payments
+}
+
+// HasCardIdOrToken todos os pagamentos com cartão usam card id ou token
 func (this *Order) HasCardIdOrToken() bool {
-	if this.IsCreditCard() {
-		return this.Payments[0].HasCardIdOrToken()
+	cards := this.GetCardPayments()
+	for _, it := range cards {
+		if !it.HasCardIdOrToken() {
+			return false
+		}
 	}
-	return false
+	return len(cards) > 0
 }
 
+// HasCardToken todos os pagamentos com cartão usam token
 func (this *Order) HasCardToken() bool {
-	if this.IsCreditCard() {
-		return this.Payments[0].HasCardToken()
+	cards := this.GetCardPayments()
+	for _, it := range cards {
+		if !it.HasCardToken() {
+			return false
+		}
 	}
-	return false
+	return len(cards) > 0
+}
+
+// HasCardId todos os pagamentos com cartão usam card id
+func (this *Order) HasCardId() bool {
+	cards := this.GetCardPayments()
+	for _, it := range cards {
+		if !it.HasCardId() {
+			return false
+		}
+	}
+	return len(cards) > 0
 }
 
+// GetCard cartão do primeiro pagamento com cartão
 func (this *Order) GetCard() CardPtr {
-	if len(this.Payments) > 0 && this.Payments[0].IsCreditCard() {
-		return this.Payments[0].CreditCard.Card
+	if cards := this.GetCardPayments(); len(cards) > 0 {
+		return cards[0].GetCard()
 	}
 	return nil
 }
 
-// SetCardToken set card token and clean card sensitive information
+func (this *Order) GetCards() Cards {
+	cards := Cards{}
+	for _, it := range this.GetCardPayments() {
+		if card := it.GetCard(); card != nil {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// SetCardToken set card token and clean card sensitive information of first card payment
 func (this *Order) SetCardToken(token string) {
-	if len(this.Payments) > 0 && this.Payments[0].IsCreditCard() {
-		this.Payments[0].CreditCard.Card.Clean()
-		this.Payments[0].CreditCard.CardToken = token
+	this.SetCardTokens(token)
+}
+
+// SetCardTokens set card tokens in card payments order and clean card sensitive information
+func (this *Order) SetCardTokens(tokens ...string) {
+	for i, it := range this.GetCardPayments() {
+		if i >= len(tokens) {
+			break
+		}
+		it.SetCardToken(tokens[i])
 	}
 }
 
-func (this *Order) HasCardId() bool {
-	if this.IsCreditCard() {
-		return this.Payments[0].HasCardId()
+// GetItemsAmount total dos itens mais o frete
+func (this *Order) GetItemsAmount() int64 {
+	var amount int64
+	for _, it := range this.Items {
+		quantity := it.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+		amount += it.Amount * quantity
 	}
-	return false
+	if this.Shipping != nil {
+		amount += this.Shipping.Amount
+	}
+	return amount
+}
+
+func (this *Order) GetPaymentsAmount() int64 {
+	var amount int64
+	for _, it := range this.Payments {
+		amount += it.Amount
+	}
+	return amount
+}
+
+// IsPaymentsAmountValid a soma dos pagamentos deve ser igual ao total do pedido
+func (this *Order) IsPaymentsAmountValid() bool {
+	return this.GetPaymentsAmount() == this.GetItemsAmount()
+}
+
+// GetChargesWaitingCapture cobranças de cartão autorizadas aguardando captura
+func (this *Order) GetChargesWaitingCapture() []*Charge {
+	charges := []*Charge{}
+	for _, it := range this.Charges {
+		if it.IsWaitingCapture() {
+			charges = append(charges, it)
+		}
+	}
+	return charges
+}
+
+// GetPaidAmount soma do valor pago de todas as cobranças
+func (this *Order) GetPaidAmount() int64 {
+	var amount int64
+	for _, it := range this.Charges {
+		if it.Status == ChargePaid || it.Status == ChargeOverpaid || it.Status == ChargeUnderpaid {
+			if it.PaidAmount > 0 {
+				amount += it.PaidAmount
+			} else {
+				amount += it.Amount
+			}
+		}
+	}
+	return amount
+}
+
+// GetPaymentProgress pago integralmente, parcialmente ou nada pago
+func (this *Order) GetPaymentProgress() OrderPaymentProgress {
+
+	if len(this.Charges) == 0 {
+		return OrderUnpaid
+	}
+
+	paid := 0
+	for _, it := range this.Charges {
+		if it.Status == ChargePaid || it.Status == ChargeOverpaid {
+			paid++
+		}
+	}
+
+	switch {
+	case paid == len(this.Charges):
+		return OrderFullyPaid
+	case paid > 0 || this.GetPaidAmount() > 0:
+		return OrderPartiallyPaid
+	default:
+		return OrderUnpaid
+	}
+}
+
+func (this *Order) IsFullyPaid() bool {
+	return this.GetPaymentProgress() == OrderFullyPaid
+}
+
+func (this *Order) IsPartiallyPaid() bool {
+	return this.GetPaymentProgress() == OrderPartiallyPaid
 }
 
 func (this *Order) GetLastCharge() *optional.Optional[ChargePtr] {
@@ -355,6 +654,35 @@ func (this *Order) GetChargeId() string {
 	).GetOr("")
 }
 
+func (this *Order) IsOpen() bool {
+	return !this.Closed
+}
+
+// GetOpenCheckout returns the first checkout still open
+func (this *Order) GetOpenCheckout() *optional.Optional[CheckoutPtr] {
+	for _, checkout := range this.Checkouts {
+		if checkout.Status == CheckoutOpen {
+			return optional.Of[CheckoutPtr](checkout)
+		}
+	}
+	return optional.OfNone[CheckoutPtr]()
+}
+
+func (this *Order) ToPaymentStatus() api.PaymentStatus {
+	switch this.Status {
+	case OrderPending:
+		return api.PaymentWaitingPayment
+	case OrderPaid:
+		return api.PaymentPaid
+	case OrderCanceled:
+		return api.PaymentCancelled
+	case OrderFailed:
+		return api.PaymentRefused
+	default:
+		return api.PaymentOther
+	}
+}
+
 func (this *Order) GetPayZenSOAPStatus() api.TransactionStatus {
 	return optional.FlatMap[LastTransactionPtr, api.TransactionStatus](
 		this.GetLastTransaction(),
@@ -371,6 +699,23 @@ func NewOrder() *Order {
 	return &Order{Payments: []*Payment{}, Items: []*OrderItem{}, Metadata: make(map[string]string)}
 }
 
+// NewOpenOrder cria um pedido aberto (comanda), que pode ser criado sem pagamentos
+func NewOpenOrder() *Order {
+	return NewOrder().AsOpen()
+}
+
+// AsOpen marca o pedido para ser criado aberto, sem exigir pagamentos
+func (this *Order) AsOpen() *Order {
+	this.Closed = false
+	this.openRequested = true
+	return this
+}
+
+// IsOpenRequested informa se o pedido foi marcado explicitamente para ser criado aberto
+func (this *Order) IsOpenRequested() bool {
+	return this.openRequested && !this.Closed
+}
+
 func (this *Order) AddItem() *OrderItem {
 	item := &OrderItem{Quantity: 1}
 	this.Items = append(this.Items, item)
@@ -405,6 +750,22 @@ func (this *Order) WithCreditCard(cb func(*CreditCard)) *Order {
 	return this
 }
 
+func (this *Order) WithDebitCard(cb func(*DebitCard)) *Order {
+	failIf(len(this.Payments) == 0, "Payments must be greater than zero")
+	payment := this.Payments[len(this.Payments)-1]
+	failIf(payment.DebitCard == nil, "DebitCard must be not nil")
+	cb(payment.DebitCard)
+	return this
+}
+
+func (this *Order) WithVoucher(cb func(*Voucher)) *Order {
+	failIf(len(this.Payments) == 0, "Payments must be greater than zero")
+	payment := this.Payments[len(this.Payments)-1]
+	failIf(payment.Voucher == nil, "Voucher must be not nil")
+	cb(payment.Voucher)
+	return this
+}
+
 func (this *Order) WithPix(cb func(*Pix)) *Order {
 	failIf(len(this.Payments) == 0, "Payments must be greater than zero")
 	payment := this.Payments[len(this.Payments)-1]
@@ -425,6 +786,27 @@ type OrderItem struct {
 	UpdatedAt   string `json:"updated_at,omitempty"`
 }
 
+type OrderItemPtr = *OrderItem
+type OrderItems = []OrderItemPtr
+
+func NewOrderItem(code string, description string, amount int64, quantity int64) *OrderItem {
+	return &OrderItem{Code: code, Description: description, Amount: amount, Quantity: quantity}
+}
+
+// OrderClose fecha o pedido com status paid, canceled ou failed
+type OrderClose struct {
+	Status OrderStatus `json:"status"`
+}
+
+// OrderCharge inclui uma cobrança em um pedido existente
+type OrderCharge struct {
+	OrderId  string            `json:"order_id"`
+	Amount   int64             `json:"amount"`
+	Payment  *Payment          `json:"payment"`
+	DueAt    string            `json:"due_at,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
 type Customer struct {
 	Id         string            `json:"id,omitempty"`
 	Name       string            `json:"name" valid:"Required;MaxSize(64)"`
@@ -458,14 +840,36 @@ type Address struct {
 	ZipCode   string `json:"zip_code" valid:"Required;MaxSize(8)"`
 	Line1     string `json:"line_1" valid:"Required"`
 	Line2     string `json:"line_2" valid:""`
+	Status    string `json:"status,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
 	CreatedAt string `json:"created_at,omitempty"`
 	UpdatedAt string `json:"updated_at,omitempty"`
+	DeletedAt string `json:"deleted_at,omitempty"`
 }
 
 func NewAddress() *Address {
 	return &Address{Country: BrasilCode}
 }
 
+type AddressPtr = *Address
+type Addresses = []AddressPtr
+
+// Shipping dados de entrega do pedido, informe Address ou AddressId de um endereço salvo do cliente
+type Shipping struct {
+	Amount         int64    `json:"amount"`
+	Description    string   `json:"description"`
+	RecipientName  string   `json:"recipient_name"`
+	RecipientPhone string   `json:"recipient_phone"`
+	AddressId      string   `json:"address_id,omitempty"`
+	Address        *Address `json:"address,omitempty"`
+	MaxDeliveryDate string  `json:"max_delivery_date,omitempty"`
+	EstimatedDeliveryDate string `json:"estimated_delivery_date,omitempty"`
+}
+
+func NewShipping(amount int64, description string, addressId string) *Shipping {
+	return &Shipping{Amount: amount, Description: description, AddressId: addressId}
+}
+
 type Phones struct {
 	HomePhone   *Phone `json:"home_phone"`
 	MobilePhone *Phone `json:"mobile_phone"`
@@ -488,26 +892,93 @@ func (this *Phone) ToString() string {
 type Payment struct {
 	PaymentMethod PaymentMethod `json:"payment_method"`
 	CreditCard    *CreditCard   `json:"credit_card,omitempty"`
+	DebitCard     *DebitCard    `json:"debit_card,omitempty"`
+	Voucher       *Voucher      `json:"voucher,omitempty"`
 	Boleto        *Boleto       `json:"boleto,omitempty"`
 	Pix           *Pix          `json:"pix,omitempty"`
 	Amount        int64         `json:"amount" valid:"Required"`
+	Split         []*Split      `json:"split,omitempty"`
+	Checkout      *CheckoutPayment `json:"checkout,omitempty"`
+}
+
+func (this *Payment) AddSplit(splits ...*Split) *Payment {
+	this.Split = append(this.Split, splits...)
+	return this
 }
 
 func (this *Payment) IsCreditCard() bool {
 	return this.PaymentMethod == MethodCreditCard
 }
 
+func (this *Payment) IsDebitCard() bool {
+	return this.PaymentMethod == MethodDebitCard
+}
+
+func (this *Payment) IsVoucher() bool {
+	return this.PaymentMethod == MethodVoucher
+}
+
+// IsCard credit_card, debit_card ou voucher
+func (this *Payment) IsCard() bool {
+	return this.IsCreditCard() || this.IsDebitCard() || this.IsVoucher()
+}
+
 func (this *Payment) HasCardIdOrToken() bool {
-	return this.IsCreditCard() &&
+	return this.IsCard() &&
 		(this.HasCardId() || this.HasCardToken())
 }
 
 func (this *Payment) HasCardId() bool {
-	return this.IsCreditCard() && len(this.CreditCard.CardId) > 0
+	_, cardId, _ := this.cardData()
+	return len(cardId) > 0
 }
 
 func (this *Payment) HasCardToken() bool {
-	return this.IsCreditCard() && len(this.CreditCard.CardToken) > 0
+	_, _, cardToken := this.cardData()
+	return len(cardToken) > 0
+}
+
+func (this *Payment) GetCard() CardPtr {
+	card, _, _ := this.cardData()
+	return card
+}
+
+// SetCardToken set card token and clean card sensitive information
+func (this *Payment) SetCardToken(token string) {
+	if card := this.GetCard(); card != nil {
+		card.Clean()
+	}
+	switch {
+	case this.IsCreditCard() && this.CreditCard != nil:
+		this.CreditCard.CardToken = token
+	case this.IsDebitCard() && this.DebitCard != nil:
+		this.DebitCard.CardToken = token
+	case this.IsVoucher() && this.Voucher != nil:
+		this.Voucher.CardToken = token
+	}
+}
+
+// SetAuthentication define a autenticação 3DS do pagamento com cartão de crédito ou débito
+func (this *Payment) SetAuthentication(authentication *PaymentAuthentication) *Payment {
+	switch {
+	case this.IsCreditCard() && this.CreditCard != nil:
+		this.CreditCard.Authentication = authentication
+	case this.IsDebitCard() && this.DebitCard != nil:
+		this.DebitCard.Authentication = authentication
+	}
+	return this
+}
+
+func (this *Payment) cardData() (CardPtr, string, string) {
+	switch {
+	case this.IsCreditCard() && this.CreditCard != nil:
+		return this.CreditCard.Card, this.CreditCard.CardId, this.CreditCard.CardToken
+	case this.IsDebitCard() && this.DebitCard != nil:
+		return this.DebitCard.Card, this.DebitCard.CardId, this.DebitCard.CardToken
+	case this.IsVoucher() && this.Voucher != nil:
+		return this.Voucher.Card, this.Voucher.CardId, this.Voucher.CardToken
+	}
+	return nil, "", ""
 }
 
 
@@ -517,10 +988,16 @@ func NewPayment(amount int64, method PaymentMethod) *Payment {
 	switch method {
 	case MethodCreditCard:
 		return &Payment{PaymentMethod: method, Amount: amount, CreditCard: NewCreditCard()}
+	case MethodDebitCard:
+		return &Payment{PaymentMethod: method, Amount: amount, DebitCard: NewDebitCard()}
+	case MethodVoucher:
+		return &Payment{PaymentMethod: method, Amount: amount, Voucher: NewVoucher()}
 	case MethodBoleto:
 		return &Payment{PaymentMethod: method, Amount: amount, Boleto: NewBoleto()}
 	case MethodPix:
 		return &Payment{PaymentMethod: method, Amount: amount, Pix: NewPix()}
+	case MethodCheckout:
+		return &Payment{PaymentMethod: method, Amount: amount, Checkout: NewCheckoutPayment()}
 	default:
 		fail("payment method %v not found", method)
 		logs.Debug("payment method %v not found", method)
@@ -536,12 +1013,74 @@ type CreditCard struct {
 	Card                *Card         `json:"card,omitempty"`
 	CardId string `json:"card_id,omitempty"`
 	CardToken string `json:"card_token,omitempty"`
+	Authentication      *PaymentAuthentication `json:"authentication,omitempty"`
 }
 
 func NewCreditCard() *CreditCard {
 	return &CreditCard{OperationType: AuthAndCapture, Installments: 1, Card: NewCard()}
 }
 
+type DebitCard struct {
+	StatementDescriptor string                 `json:"statement_descriptor" valid:"Required;MaxSize(13)"`
+	Card                *Card                  `json:"card,omitempty"`
+	CardId              string                 `json:"card_id,omitempty"`
+	CardToken           string                 `json:"card_token,omitempty"`
+	Authentication      *PaymentAuthentication `json:"authentication,omitempty"`
+}
+
+func NewDebitCard() *DebitCard {
+	return &DebitCard{Card: NewCard()}
+}
+
+// Voucher vale refeição/alimentação, o documento do portador é obrigatório
+type Voucher struct {
+	StatementDescriptor string `json:"statement_descriptor" valid:"Required;MaxSize(13)"`
+	Card                *Card  `json:"card,omitempty"`
+	CardId              string `json:"card_id,omitempty"`
+	CardToken           string `json:"card_token,omitempty"`
+}
+
+func NewVoucher() *Voucher {
+	return &Voucher{Card: NewCard()}
+}
+
+type PaymentAuthentication struct {
+	Type         AuthenticationType `json:"type"`
+	ThreeDSecure *ThreeDSecure      `json:"threed_secure,omitempty"`
+}
+
+type ThreeDSecure struct {
+	Mpi             ThreeDSecureMpi `json:"mpi"`
+	Eci             string          `json:"eci,omitempty"`
+	Cavv            string          `json:"cavv,omitempty"`
+	TransactionId   string          `json:"transaction_id,omitempty"`
+	DsTransactionId string          `json:"ds_transaction_id,omitempty"`
+	Version         string          `json:"version,omitempty"`
+	SuccessUrl      string          `json:"success_url,omitempty"`
+}
+
+// NewAcquirerThreeDSecure autenticação pela adquirente, o cliente volta para successUrl
+func NewAcquirerThreeDSecure(successUrl string) *PaymentAuthentication {
+	return &PaymentAuthentication{
+		Type:         AuthenticationThreeDSecure,
+		ThreeDSecure: &ThreeDSecure{Mpi: MpiAcquirer, SuccessUrl: successUrl},
+	}
+}
+
+// NewThirdPartyThreeDSecure autenticação já realizada por um MPI externo
+func NewThirdPartyThreeDSecure(transactionId string, cavv string, eci string, version string) *PaymentAuthentication {
+	return &PaymentAuthentication{
+		Type: AuthenticationThreeDSecure,
+		ThreeDSecure: &ThreeDSecure{
+			Mpi:           MpiThirdParty,
+			TransactionId: transactionId,
+			Cavv:          cavv,
+			Eci:           eci,
+			Version:       version,
+		},
+	}
+}
+
 
 type Card struct {
 	Number           string          `json:"number,omitempty" valid:""`
@@ -776,16 +1315,68 @@ func NewPlanItem(name string, quantity Quantity, cycles CycleCount, price int64)
 	}
 }
 
+type PlanItemPtr = *PlanItem
+type PlanItems = []PlanItemPtr
+
 type PricingScheme struct {
-	Price        int64      `json:"price"`
-	MininumPrice int64      `json:"mininum_price,omitempty"`
-	SchemeType   SchemeType `json:"scheme_type,omitempty"`
+	Price         int64           `json:"price"`
+	MininumPrice  int64           `json:"mininum_price,omitempty"`
+	SchemeType    SchemeType      `json:"scheme_type,omitempty"`
+	PriceBrackets []*PriceBracket `json:"price_brackets,omitempty"` // faixas de preço para package, volume e tier
 }
 
 func NewPricingScheme(price int64) *PricingScheme {
 	return &PricingScheme{Price: price, SchemeType: Unit}
 }
 
+// NewTieredPricingScheme cobrança por uso com faixas de preço (package, volume ou tier)
+func NewTieredPricingScheme(schemeType SchemeType, brackets ...*PriceBracket) *PricingScheme {
+	return &PricingScheme{SchemeType: schemeType, PriceBrackets: brackets}
+}
+
+func (this *PricingScheme) AddBracket(startQuantity int64, endQuantity int64, price int64) *PricingScheme {
+	this.PriceBrackets = append(this.PriceBrackets, NewPriceBracket(startQuantity, endQuantity, price))
+	return this
+}
+
+func (this *PricingScheme) IsTiered() bool {
+	return this.SchemeType == Package || this.SchemeType == Volume || this.SchemeType == Tier
+}
+
+// PriceBracket faixa de preço, EndQuantity zero indica a última faixa sem limite
+type PriceBracket struct {
+	StartQuantity int64 `json:"start_quantity"`
+	EndQuantity   int64 `json:"end_quantity,omitempty"`
+	Price         int64 `json:"price"`
+	OveragePrice  int64 `json:"overage_price,omitempty"`
+}
+
+func NewPriceBracket(startQuantity int64, endQuantity int64, price int64) *PriceBracket {
+	return &PriceBracket{StartQuantity: startQuantity, EndQuantity: endQuantity, Price: price}
+}
+
+// Usage uso de um item da assinatura no ciclo, usado na cobrança por consumo
+type Usage struct {
+	Id               string            `json:"id,omitempty"`
+	Quantity         int64             `json:"quantity"`
+	Description      string            `json:"description"`
+	UsedAt           string            `json:"used_at"`
+	Code             string            `json:"code,omitempty"`
+	Group            string            `json:"group,omitempty"`
+	Amount           int64             `json:"amount,omitempty"`
+	Status           string            `json:"status,omitempty"`
+	CreatedAt        string            `json:"created_at,omitempty"`
+	DeletedAt        string            `json:"deleted_at,omitempty"`
+	SubscriptionItem *SubscriptionItem `json:"subscription_item,omitempty"`
+}
+
+func NewUsage(quantity int64, description string, usedAt time.Time) *Usage {
+	return &Usage{Quantity: quantity, Description: description, UsedAt: usedAt.Format(time.RFC3339)}
+}
+
+type UsagePtr = *Usage
+type Usages = []UsagePtr
+
 type AdditionalInformation struct {
 	Name  string `json:"name" valid:"Required"`
 	Value string `json:"value" valid:"Required"`
@@ -820,6 +1411,45 @@ type Subscription struct {
 	PricingScheme       *PricingScheme      `json:"pricing_scheme,omitempty"`
 	Quantity            Quantity            `json:"quantity"`
 	Boleto              *Boleto             `json:"boleto,omitempty"`
+	Split               *SubscriptionSplit  `json:"split,omitempty"`
+	BillingAddressId    string              `json:"billing_address_id,omitempty"`
+	NextBillingAt       string              `json:"next_billing_at,omitempty"`
+	CurrentCycle        *Cycle              `json:"current_cycle,omitempty"`
+}
+
+// SetBillingAddressId usa um endereço salvo do cliente como endereço de cobrança
+func (this *Subscription) SetBillingAddressId(addressId string) *Subscription {
+	this.BillingAddressId = addressId
+	if this.Card != nil {
+		this.Card.BillingAddressId = addressId
+		this.Card.BillingAddress = nil
+	}
+	return this
+}
+
+// GetCycleAmount valor de cada ciclo pelos itens, sem descontos e incrementos
+func (this *Subscription) GetCycleAmount() int64 {
+	var amount int64
+	for _, it := range this.Items {
+		if it.PricingScheme != nil {
+			amount += it.PricingScheme.Price * int64(it.Quantity)
+		}
+	}
+	return amount
+}
+
+func (this *Subscription) AddSplit(splits ...*Split) *Subscription {
+	if this.Split == nil {
+		this.Split = &SubscriptionSplit{Enabled: true}
+	}
+	this.Split.Rules = append(this.Split.Rules, splits...)
+	return this
+}
+
+// SubscriptionSplit split aplicado em todas as cobranças da assinatura
+type SubscriptionSplit struct {
+	Enabled bool     `json:"enabled"`
+	Rules   []*Split `json:"rules"`
 }
 
 func NewSubscription() *Subscription {
@@ -929,18 +1559,60 @@ func (this *SubscriptionItem) SetPricingScheme(scheme *PricingScheme) *Subscript
 	return this
 }
 
+// Discount desconto na assinatura ou em um item da assinatura (ItemId).
+// Cycles zero aplica o desconto em todos os ciclos
 type Discount struct {
-	Value         int64         `json":"value"`
-	Cycles        int64         `json":"cycles"`
-	IncrementType OperationType `json:"increment_type,omitempty"`
+	Id           string            `json:"id,omitempty"`
+	Value        int64             `json:"value"`
+	Cycles       int64             `json:"cycles,omitempty"`
+	DiscountType OperationType     `json:"discount_type"`
+	ItemId       string            `json:"item_id,omitempty"`
+	Item         *SubscriptionItem `json:"subscription_item,omitempty"`
+	Status       string            `json:"status,omitempty"`
+	CreatedAt    string            `json:"created_at,omitempty"`
+}
+
+func NewDiscount(value int64, discountType OperationType, cycles int64) *Discount {
+	return &Discount{Value: value, DiscountType: discountType, Cycles: cycles}
+}
+
+func (this *Discount) GetItemId() string {
+	if len(this.ItemId) == 0 && this.Item != nil {
+		return this.Item.Id
+	}
+	return this.ItemId
 }
 
+type DiscountPtr = *Discount
+type Discounts = []DiscountPtr
+
+// Increment incremento na assinatura ou em um item da assinatura (ItemId).
+// Cycles zero aplica o incremento em todos os ciclos
 type Increment struct {
-	Value         int64         `json":"value"`
-	Cycles        int64         `json":"cycles"`
-	IncrementType OperationType `json:"increment_type,omitempty"`
+	Id            string            `json:"id,omitempty"`
+	Value         int64             `json:"value"`
+	Cycles        int64             `json:"cycles,omitempty"`
+	IncrementType OperationType     `json:"increment_type"`
+	ItemId        string            `json:"item_id,omitempty"`
+	Item          *SubscriptionItem `json:"subscription_item,omitempty"`
+	Status        string            `json:"status,omitempty"`
+	CreatedAt     string            `json:"created_at,omitempty"`
+}
+
+func NewIncrement(value int64, incrementType OperationType, cycles int64) *Increment {
+	return &Increment{Value: value, IncrementType: incrementType, Cycles: cycles}
 }
 
+func (this *Increment) GetItemId() string {
+	if len(this.ItemId) == 0 && this.Item != nil {
+		return this.Item.Id
+	}
+	return this.ItemId
+}
+
+type IncrementPtr = *Increment
+type Increments = []IncrementPtr
+
 type Cycle struct {
 	Id        string `json:"id"`
 	BillingAt string `json:"billing_at"`
@@ -953,6 +1625,17 @@ type Cycle struct {
 	Status    string `json:"status"`
 }
 
+func (this *Cycle) IsBilled() bool {
+	return this.Status == "billed"
+}
+
+func (this *Cycle) IsClosed() bool {
+	return this.Status == "closed"
+}
+
+type CyclePtr = *Cycle
+type Cycles = []CyclePtr
+
 // The object invoice representa os documentos gerados automaticamente ao final
 // de cada ciclo de uma assinatura, discriminando todos os valores referentes à
 // assinatura, como itens e descontos, para realização da cobrança do assinante.
@@ -1141,6 +1824,8 @@ type Charge struct {
 	PaymentMethod   PaymentMethod    `json:"payment_method"`
 	DueAt           string           `json:"due_at"`
 	PaidAt          string           `json:"paid_at"`
+	CanceledAmount  int64            `json:"canceled_amount"`
+	CanceledAt      string           `json:"canceled_at"`
 	CreatedAt       string           `json:"created_at"`
 	UpdatedAt       string           `json:"updated_at"`
 	Invoice         *Invoice         `json:"invoice"`
@@ -1168,9 +1853,159 @@ func (this *Charge) ToPaymentStatus() api.PaymentStatus {
 	}
 }
 
+func (this *Charge) GetTransactionStatus() api.PagarmeV5Status {
+	if this.LastTransaction != nil {
+		return this.LastTransaction.Status
+	}
+	return api.PagarmeV5None
+}
+
+func (this *Charge) IsPartialCapture() bool {
+	return this.GetTransactionStatus() == api.PagarmeV5PartialCapture ||
+		(this.PaidAmount > 0 && this.PaidAmount < this.Amount)
+}
+
+func (this *Charge) IsPartialRefunded() bool {
+	switch this.GetTransactionStatus() {
+	case api.PagarmeV5PartialRefunded, api.PagarmeV5PartialVoid:
+		return true
+	}
+	return this.CanceledAmount > 0 && this.CanceledAmount < this.Amount
+}
+
+// GetCapturableAmount returns the amount still waiting capture. Pagarme
+// accepts only one capture, so after capture it's zero
+func (this *Charge) GetCapturableAmount() int64 {
+	switch this.GetTransactionStatus() {
+	case api.PagarmeV5AuthorizedPendingCapture, api.PagarmeV5WaitingCapture:
+		return this.Amount - this.CanceledAmount
+	}
+	return 0
+}
+
+// IsCard cobrança de cartão de crédito, a única que pode ser autorizada e capturada depois
+func (this *Charge) IsCard() bool {
+	return this.PaymentMethod == MethodCreditCard
+}
+
+// IsWaitingCapture cobrança de cartão autorizada aguardando captura
+func (this *Charge) IsWaitingCapture() bool {
+	return this.IsCard() && this.GetCapturableAmount() > 0
+}
+
+// IsCancelable cobrança pendente, autorizada ou paga que ainda pode ser cancelada
+func (this *Charge) IsCancelable() bool {
+	switch this.Status {
+	case ChargeCanceled, ChargeFailed:
+		return false
+	}
+	return true
+}
+
+// GetRefundableAmount returns the amount that can still be cancelled
+func (this *Charge) GetRefundableAmount() int64 {
+	if capturable := this.GetCapturableAmount(); capturable > 0 {
+		return capturable
+	}
+	if refundable := this.PaidAmount - this.CanceledAmount; refundable > 0 {
+		return refundable
+	}
+	return 0
+}
+
+func (this *Charge) GetSplits() SplitResults {
+	if this.LastTransaction != nil {
+		return this.LastTransaction.Split
+	}
+	return nil
+}
+
+// GetSplitBreakdown valor em centavos por recebedor. Percentuais são calculados
+// sobre o valor pago, ou sobre o valor da cobrança se ainda não foi paga
+func (this *Charge) GetSplitBreakdown() map[string]int64 {
+	base := this.PaidAmount
+	if base == 0 {
+		base = this.Amount
+	}
+	breakdown := make(map[string]int64)
+	for _, it := range this.GetSplits() {
+		switch it.Type {
+		case Percentage:
+			breakdown[it.GetRecipientId()] += base * it.Amount / 100
+		default:
+			breakdown[it.GetRecipientId()] += it.Amount
+		}
+	}
+	return breakdown
+}
+
 type ChargePtr = *Charge
 type Charges = []ChargePtr
 
+// ChargeCapture captura total ou parcial, sem amount captura o valor total
+type ChargeCapture struct {
+	Code   string   `json:"code,omitempty"`
+	Amount int64    `json:"amount,omitempty"`
+	Split  []*Split `json:"split,omitempty"`
+}
+
+// ChargeCancel cancelamento total ou parcial, sem amount estorna o valor total
+type ChargeCancel struct {
+	Amount int64    `json:"amount,omitempty"`
+	Split  []*Split `json:"split,omitempty"`
+}
+
+// Split regra de divisão do valor entre recebedores
+type Split struct {
+	Amount      int64         `json:"amount"`
+	RecipientId string        `json:"recipient_id"`
+	Type        OperationType `json:"type"` // flat ou percentage
+	Options     *SplitOptions `json:"options,omitempty"`
+}
+
+type SplitOptions struct {
+	Liable              bool `json:"liable"`                // responsável pelo chargeback
+	ChargeProcessingFee bool `json:"charge_processing_fee"` // responsável pela taxa de processamento
+	ChargeRemainderFee  bool `json:"charge_remainder_fee"`  // recebe o resto da divisão
+}
+
+type SplitPtr = *Split
+type Splits = []SplitPtr
+
+// SplitResult divisão aplicada na transação
+type SplitResult struct {
+	Id        string        `json:"id"`
+	Type      OperationType `json:"type"`
+	Amount    int64         `json:"amount"`
+	GatewayId string        `json:"gateway_id"`
+	Status    string        `json:"status"`
+	Recipient *Recipient    `json:"recipient"`
+	Options   *SplitOptions `json:"options"`
+}
+
+func (this *SplitResult) GetRecipientId() string {
+	if this.Recipient != nil {
+		return this.Recipient.Id
+	}
+	return ""
+}
+
+type SplitResultPtr = *SplitResult
+type SplitResults = []SplitResultPtr
+
+func NewSplit(recipientId string, amount int64, splitType OperationType) *Split {
+	return &Split{RecipientId: recipientId, Amount: amount, Type: splitType}
+}
+
+func (this *Split) WithOptions(liable bool, chargeProcessingFee bool, chargeRemainderFee bool) *Split {
+	this.Options = &SplitOptions{
+		Liable:              liable,
+		ChargeProcessingFee: chargeProcessingFee,
+		ChargeRemainderFee:  chargeRemainderFee,
+	}
+	return this
+}
+
 type ChargeUpdate struct {
 	UpdateSubscription bool   `json:"update_subscription,omitempty"`
 	CardId             string `json:"card_id,omitempty"`
@@ -1178,8 +2013,97 @@ type ChargeUpdate struct {
 	Card               *Card  `json:"card,omitempty"`
 }
 
+// Checkout representa a página de pagamento hospedada do pedido
 type Checkout struct {
-	// Define a estrutura de acordo com o JSON dos checkouts, se necessário.
+	Id                          string            `json:"id"`
+	Currency                    Currency          `json:"currency"`
+	Amount                      int64             `json:"amount"`
+	Status                      CheckoutStatus    `json:"status"`
+	PaymentUrl                  string            `json:"payment_url"`
+	SuccessUrl                  string            `json:"success_url"`
+	DefaultPaymentMethod        PaymentMethod     `json:"default_payment_method"`
+	AcceptedPaymentMethods      []PaymentMethod   `json:"accepted_payment_methods"`
+	AcceptedMultiPaymentMethods [][]PaymentMethod `json:"accepted_multi_payment_methods"`
+	CustomerEditable            bool              `json:"customer_editable"`
+	BillingAddressEditable      bool              `json:"billing_address_editable"`
+	SkipCheckoutSuccessPage     bool              `json:"skip_checkout_success_page"`
+	Shippable                   bool              `json:"shippable"`
+	Customer                    *Customer         `json:"customer"`
+	BillingAddress              *Address          `json:"billing_address"`
+	CreatedAt                   string            `json:"created_at"`
+	UpdatedAt                   string            `json:"updated_at"`
+	ExpiresAt                   string            `json:"expires_at"`
+	ClosedAt                    string            `json:"closed_at"`
+	CanceledAt                  string            `json:"canceled_at"`
+	Metadata                    map[string]string `json:"metadata"`
+}
+
+type CheckoutPtr = *Checkout
+type Checkouts = []CheckoutPtr
+
+// CheckoutPayment configuração do link de pagamento, usado com payment_method checkout
+type CheckoutPayment struct {
+	ExpiresIn               int64               `json:"expires_in,omitempty"` // expiração do link em minutos
+	DefaultPaymentMethod    PaymentMethod       `json:"default_payment_method,omitempty"`
+	AcceptedPaymentMethods  []PaymentMethod     `json:"accepted_payment_methods"`
+	SuccessUrl              string              `json:"success_url,omitempty"`
+	SkipCheckoutSuccessPage bool                `json:"skip_checkout_success_page"`
+	CustomerEditable        bool                `json:"customer_editable"`
+	BillingAddressEditable  bool                `json:"billing_address_editable"`
+	BillingAddress          *Address            `json:"billing_address,omitempty"`
+	BillingAddressId        string              `json:"billing_address_id,omitempty"`
+	CreditCard              *CheckoutCreditCard `json:"credit_card,omitempty"`
+	Boleto                  *CheckoutBoleto     `json:"boleto,omitempty"`
+	Pix                     *CheckoutPix        `json:"pix,omitempty"`
+}
+
+func NewCheckoutPayment() *CheckoutPayment {
+	return &CheckoutPayment{CustomerEditable: true, BillingAddressEditable: true}
+}
+
+func (this *CheckoutPayment) Accept(methods ...PaymentMethod) *CheckoutPayment {
+	this.AcceptedPaymentMethods = append(this.AcceptedPaymentMethods, methods...)
+	return this
+}
+
+func (this *CheckoutPayment) IsAccepted(method PaymentMethod) bool {
+	for _, it := range this.AcceptedPaymentMethods {
+		if it == method {
+			return true
+		}
+	}
+	return false
+}
+
+type CheckoutCreditCard struct {
+	Capture             bool                   `json:"capture"`
+	StatementDescriptor string                 `json:"statement_descriptor,omitempty"`
+	Installments        []*CheckoutInstallment `json:"installments,omitempty"`
+}
+
+// NewCheckoutCreditCard opções de parcelamento sem juros de 1 até maxInstallments
+func NewCheckoutCreditCard(amount int64, maxInstallments int64) *CheckoutCreditCard {
+	card := &CheckoutCreditCard{Capture: true}
+	for i := int64(1); i <= maxInstallments; i++ {
+		card.Installments = append(card.Installments, &CheckoutInstallment{Number: i, Total: amount})
+	}
+	return card
+}
+
+type CheckoutInstallment struct {
+	Number int64 `json:"number"`
+	Total  int64 `json:"total"` // valor total com juros da opção de parcelamento
+}
+
+type CheckoutBoleto struct {
+	Bank         BankCode `json:"bank,omitempty"`
+	Instructions string   `json:"instructions,omitempty"`
+	DueAt        string   `json:"due_at,omitempty"`
+}
+
+type CheckoutPix struct {
+	ExpiresIn             int64                  `json:"expires_in,omitempty"` // expiração do Pix em segundos
+	AdditionalInformation *AdditionalInformation `json:"additional_information,omitempty"`
 }
 
 type LastTransaction struct {
@@ -1207,6 +2131,7 @@ type LastTransaction struct {
 	GatewayResponse     *GatewayResponse    `json:"gateway_response"`
 	AntifraudResponse   *AntifraudResponse  `json:"antifraud_response"`
 	Metadata            map[string]string   `json:"metadata"`
+	Split               []*SplitResult      `json:"split,omitempty"`
 
 	Url         string `json:"url,omitempty"`
 	Pdf         string `json:"pdf,omitempty"`
@@ -1216,9 +2141,46 @@ type LastTransaction struct {
 	QrCodeUrl   string `json:"qr_code_url,omitempty"`
 	ExpiresAt   string `json:"expires_at,omitempty"`
 	NossoNumero string `json:"nosso_numero,omitempty"`
+
+	// debit_card com 3DS pela adquirente, url para autenticação do portador
+	ThreeDAuthenticationUrl string `json:"threed_authentication_url,omitempty"`
+}
+
+func (this *LastTransaction) GetPaymentType() api.PaymentType {
+	switch PaymentMethod(this.TransactionType) {
+	case MethodCreditCard:
+		return api.PaymentTypeCreditCard
+	case MethodDebitCard:
+		return api.PaymentTypeDebitCard
+	case MethodVoucher:
+		return api.PaymentTypeVoucher
+	case MethodBoleto:
+		return api.PaymentTypeBoleto
+	case MethodPix:
+		return api.PaymentTypePix
+	default:
+		return api.PaymentTypeUndefined
+	}
+}
+
+// IsWaitingAuthentication transação aguardando autenticação 3DS do portador
+func (this *LastTransaction) IsWaitingAuthentication() bool {
+	if len(this.ThreeDAuthenticationUrl) == 0 {
+		return false
+	}
+	switch this.Status {
+	case api.PagarmeV5Generated, api.PagarmeV5Processing, api.PagarmeV5WaitingPayment, api.PagarmeV5None, "":
+		return true
+	}
+	return false
 }
 
 func (this *LastTransaction) GetPayZenSOAPStatus() api.TransactionStatus {
+
+	if this.IsWaitingAuthentication() {
+		return api.WaitingAuthorisation
+	}
+
 	switch this.Status {
 	case api.PagarmeV5Generated:
 		return api.Created
@@ -1265,6 +2227,8 @@ type BankAccount struct {
 	HolderType        CustomerType    `json:"holder_type" valid:"Required"`
 	HolderDocument    string          `json:"holder_document" valid:"Required"`
 	Type              BankAccountType `json:"type" valid:"Required"`
+	Id                string          `json:"id,omitempty"`
+	Status            string          `json:"status,omitempty"`
 }
 
 // The object recipient represents a receiver,
@@ -1280,6 +2244,173 @@ type Recipient struct {
 	TransferSettings   *TransferSettings `json:"transfer_settings" valid:"Required"`
 	DefaultBankAccount BankAccount       `json:"default_bank_account" valid:"Required"`
 	Metadata           map[string]string `json:"metadata"`
+
+	Status              RecipientStatus      `json:"status,omitempty"`
+	KycDetails          *KycDetails          `json:"kyc_details,omitempty"`
+	RegisterInformation *RegisterInformation `json:"register_information,omitempty"`
+	CreatedAt           string               `json:"created_at,omitempty"`
+	UpdatedAt           string               `json:"updated_at,omitempty"`
+}
+
+func (this *Recipient) IsActive() bool {
+	return this.Status == RecipientActive
+}
+
+func (this *Recipient) GetKycStatus() KycStatus {
+	if this.KycDetails != nil {
+		return this.KycDetails.Status
+	}
+	return ""
+}
+
+// PendingRequirements o que falta para o recebedor receber transferências
+func (this *Recipient) PendingRequirements() []RecipientRequirement {
+	var pending []RecipientRequirement
+
+	switch this.GetKycStatus() {
+	case KycApproved:
+	case KycDenied, KycPartiallyDenied:
+		pending = append(pending, RequirementKycDenied)
+	default:
+		pending = append(pending, RequirementKyc)
+	}
+
+	if len(this.DefaultBankAccount.AccountNumber) == 0 {
+		pending = append(pending, RequirementBankAccount)
+	}
+
+	switch this.Status {
+	case RecipientActive:
+	case RecipientRefused, RecipientSuspended, RecipientBlocked, RecipientInactive:
+		pending = append(pending, RequirementBlocked)
+	default:
+		pending = append(pending, RequirementActivation)
+	}
+
+	return pending
+}
+
+func (this *Recipient) CanReceiveTransfers() bool {
+	return len(this.PendingRequirements()) == 0
+}
+
+type RecipientStatus string
+type KycStatus string
+type RecipientRequirement string
+type RegisterType string
+
+const (
+	RecipientRegistration RecipientStatus = "registration"
+	RecipientAffiliation  RecipientStatus = "affiliation"
+	RecipientActive       RecipientStatus = "active"
+	RecipientRefused      RecipientStatus = "refused"
+	RecipientSuspended    RecipientStatus = "suspended"
+	RecipientBlocked      RecipientStatus = "blocked"
+	RecipientInactive     RecipientStatus = "inactive"
+)
+
+const (
+	KycPending         KycStatus = "pending"
+	KycApproved        KycStatus = "approved"
+	KycDenied          KycStatus = "denied"
+	KycPartiallyDenied KycStatus = "partially_denied"
+)
+
+const (
+	RequirementKyc         RecipientRequirement = "kyc"          // verificação de identidade pendente, gere o link com KycLink
+	RequirementKycDenied   RecipientRequirement = "kyc_denied"   // verificação negada, ver KycDetails.StatusReason
+	RequirementBankAccount RecipientRequirement = "bank_account" // conta bancária padrão não informada
+	RequirementActivation  RecipientRequirement = "activation"   // aguardando credenciamento
+	RequirementBlocked     RecipientRequirement = "blocked"      // recusado, suspenso ou bloqueado
+)
+
+const (
+	RegisterIndividual  RegisterType = "individual"
+	RegisterCorporation RegisterType = "corporation"
+)
+
+type KycDetails struct {
+	Status       KycStatus `json:"status"`
+	StatusReason string    `json:"status_reason"`
+}
+
+type KycLink struct {
+	Url            string `json:"url"`
+	Base64QrCode   string `json:"base64_qrcode"`
+	ExpirationDate string `json:"expiration_date"`
+}
+
+type KycLinkPtr = *KycLink
+
+// RecipientRegister cadastro de recebedor com dados de KYC
+type RecipientRegister struct {
+	Code                string               `json:"code"`
+	RegisterInformation *RegisterInformation `json:"register_information"`
+	DefaultBankAccount  *BankAccount         `json:"default_bank_account"`
+	TransferSettings    *TransferSettings    `json:"transfer_settings,omitempty"`
+	Metadata            map[string]string    `json:"metadata,omitempty"`
+}
+
+// RegisterInformation dados cadastrais, pessoa física (individual) ou jurídica (corporation)
+type RegisterInformation struct {
+	Type         RegisterType      `json:"type"`
+	Email        string            `json:"email"`
+	Document     string            `json:"document"`
+	SiteUrl      string            `json:"site_url,omitempty"`
+	PhoneNumbers []*RegisterPhone  `json:"phone_numbers,omitempty"`
+
+	// individual
+	Name                   string           `json:"name,omitempty"`
+	MotherName             string           `json:"mother_name,omitempty"`
+	Birthdate              string           `json:"birthdate,omitempty"` // dd/mm/yyyy
+	MonthlyIncome          int64            `json:"monthly_income,omitempty"`
+	ProfessionalOccupation string           `json:"professional_occupation,omitempty"`
+	Address                *RegisterAddress `json:"address,omitempty"`
+
+	// corporation
+	CompanyName      string             `json:"company_name,omitempty"`
+	TradingName      string             `json:"trading_name,omitempty"`
+	AnnualRevenue    int64              `json:"annual_revenue,omitempty"`
+	CorporationType  string             `json:"corporation_type,omitempty"`
+	FoundingDate     string             `json:"founding_date,omitempty"`
+	MainAddress      *RegisterAddress   `json:"main_address,omitempty"`
+	ManagingPartners []*ManagingPartner `json:"managing_partners,omitempty"`
+}
+
+func (this *RegisterInformation) IsCorporation() bool {
+	return this.Type == RegisterCorporation
+}
+
+// ManagingPartner sócio administrador da empresa
+type ManagingPartner struct {
+	Name                            string           `json:"name"`
+	Email                           string           `json:"email"`
+	Document                        string           `json:"document"`
+	Type                            RegisterType     `json:"type"`
+	MotherName                      string           `json:"mother_name,omitempty"`
+	Birthdate                       string           `json:"birthdate"`
+	MonthlyIncome                   int64            `json:"monthly_income"`
+	ProfessionalOccupation          string           `json:"professional_occupation"`
+	SelfDeclaredLegalRepresentative bool             `json:"self_declared_legal_representative"`
+	Address                         *RegisterAddress `json:"address"`
+	PhoneNumbers                    []*RegisterPhone `json:"phone_numbers"`
+}
+
+type RegisterAddress struct {
+	Street         string `json:"street"`
+	StreetNumber   string `json:"street_number"`
+	Complementary  string `json:"complementary,omitempty"`
+	Neighborhood   string `json:"neighborhood"`
+	City           string `json:"city"`
+	State          string `json:"state"`
+	ZipCode        string `json:"zip_code"`
+	ReferencePoint string `json:"reference_point,omitempty"`
+}
+
+type RegisterPhone struct {
+	Ddd    string `json:"ddd"`
+	Number string `json:"number"`
+	Type   string `json:"type"` // mobile ou home
 }
 
 type RecipientPtr = *Recipient
@@ -1301,14 +2432,27 @@ type Balance struct {
 type BalancePtr = *Balance
 
 type BalanceOperation struct {
-	Id             string `json:"id"`
-	Status         string `json:"status"`
-	BalanceAmount  int64  `json:"balance_amount"`
-	Type           string `json:"type"`
-	Amount         int64  `json:"amount"`
-	Fee            int64  `json:"fee"`
-	CreatedAt      string `json:"created_at"`
-	MovementObject string `json:"movement_object"`
+	Id             string          `json:"id"`
+	Status         string          `json:"status"`
+	BalanceAmount  int64           `json:"balance_amount"`
+	Type           string          `json:"type"`
+	Amount         int64           `json:"amount"`
+	Fee            int64           `json:"fee"`
+	CreatedAt      string          `json:"created_at"`
+	MovementObject *MovementObject `json:"movement_object"`
+}
+
+func (this *BalanceOperation) IsAnticipation() bool {
+	return this.Type == "anticipation" ||
+		(this.MovementObject != nil && this.MovementObject.Object == "anticipation")
+}
+
+// GetAnticipationFee taxa de antecipação da movimentação
+func (this *BalanceOperation) GetAnticipationFee() int64 {
+	if this.MovementObject != nil {
+		return this.MovementObject.AnticipationFee
+	}
+	return 0
 }
 
 type BalanceOperationPtr = *BalanceOperation
@@ -1330,6 +2474,163 @@ type MovementObject struct {
 	CreatedAt         string `json:"created_at"`
 	Type              string `json:"type"`
 	GatewayId         string `json:"gateway_id"`
+	Timeframe         AnticipationTimeframe `json:"timeframe,omitempty"` // somente antecipações
+}
+
+// Anticipation antecipação de recebíveis do recebedor. A API v5 não possui endpoint
+// de cancelamento, uma antecipação criada só pode ser cancelada pela dashboard
+type Anticipation struct {
+	Id              string                `json:"id,omitempty"`
+	Status          AnticipationStatus    `json:"status,omitempty"`
+	Amount          int64                 `json:"amount"`
+	Fee             int64                 `json:"fee,omitempty"`
+	AnticipationFee int64                 `json:"anticipation_fee,omitempty"`
+	Timeframe       AnticipationTimeframe `json:"timeframe"`
+	PaymentDate     string                `json:"payment_date"`
+	Type            string                `json:"type,omitempty"`
+	CreatedAt       string                `json:"created_at,omitempty"`
+	UpdatedAt       string                `json:"updated_at,omitempty"`
+}
+
+func NewAnticipation(amount int64, timeframe AnticipationTimeframe, paymentDate time.Time) *Anticipation {
+	return &Anticipation{Amount: amount, Timeframe: timeframe, PaymentDate: paymentDate.Format(DateLayout)}
+}
+
+// GetNetAmount valor recebido descontadas as taxas
+func (this *Anticipation) GetNetAmount() int64 {
+	return this.Amount - this.Fee - this.AnticipationFee
+}
+
+type AnticipationPtr = *Anticipation
+type Anticipations = []AnticipationPtr
+
+type AnticipationLimit struct {
+	Amount          int64 `json:"amount"`
+	AnticipationFee int64 `json:"anticipation_fee"`
+	Fee             int64 `json:"fee"`
+}
+
+// AnticipationLimits valor mínimo e máximo antecipável na data e timeframe
+type AnticipationLimits struct {
+	Max *AnticipationLimit `json:"max"`
+	Min *AnticipationLimit `json:"min"`
+}
+
+func (this *AnticipationLimits) Allows(amount int64) bool {
+	return this.Max != nil && this.Min != nil &&
+		amount >= this.Min.Amount && amount <= this.Max.Amount
+}
+
+type AnticipationLimitsPtr = *AnticipationLimits
+
+// AnticipationEstimate estimativa aproximada das taxas, proporcional aos limites, sem criar a antecipação
+type AnticipationEstimate struct {
+	Amount          int64                 `json:"amount"`
+	Timeframe       AnticipationTimeframe `json:"timeframe"`
+	PaymentDate     string                `json:"payment_date"`
+	AnticipationFee int64                 `json:"anticipation_fee"`
+	Fee             int64                 `json:"fee"`
+	NetAmount       int64                 `json:"net_amount"`
+	Limits          *AnticipationLimits   `json:"limits"`
+}
+
+type AnticipationEstimatePtr = *AnticipationEstimate
+
+type PayableStatus string
+
+const (
+	PayableWaitingFunds PayableStatus = "waiting_funds"
+	PayablePaid         PayableStatus = "paid"
+	PayablePrepaid      PayableStatus = "prepaid" // antecipado
+	PayableSuspended    PayableStatus = "suspended"
+)
+
+// Payable recebível de uma parcela da cobrança para o recebedor
+type Payable struct {
+	Id                  int64         `json:"id"`
+	Status              PayableStatus `json:"status"`
+	Amount              int64         `json:"amount"`
+	Fee                 int64         `json:"fee"`
+	AnticipationFee     int64         `json:"anticipation_fee"`
+	FraudCoverageFee    int64         `json:"fraud_coverage_fee"`
+	Installment         int64         `json:"installment"`
+	GatewayId           int64         `json:"gateway_id"`
+	ChargeId            string        `json:"charge_id"`
+	SplitId             string        `json:"split_id"`
+	AnticipationId      string        `json:"anticipation_id"`
+	RecipientId         string        `json:"recipient_id"`
+	OriginatorModel     string        `json:"originator_model"`
+	OriginatorModelId   string        `json:"originator_model_id"`
+	PaymentDate         string        `json:"payment_date"`
+	OriginalPaymentDate string        `json:"original_payment_date"`
+	Type                string        `json:"type"` // credit, refund, chargeback...
+	PaymentMethod       PaymentMethod `json:"payment_method"`
+	AccrualAt           string        `json:"accrual_at"`
+	CreatedAt           string        `json:"created_at"`
+}
+
+// GetNetAmount valor líquido a receber
+func (this *Payable) GetNetAmount() int64 {
+	return this.Amount - this.Fee - this.AnticipationFee - this.FraudCoverageFee
+}
+
+// GetPaymentDay data de pagamento sem horário (yyyy-mm-dd)
+func (this *Payable) GetPaymentDay() string {
+	if len(this.PaymentDate) >= len(DateLayout) {
+		return this.PaymentDate[:len(DateLayout)]
+	}
+	return this.PaymentDate
+}
+
+type PayablePtr = *Payable
+type Payables = []PayablePtr
+
+// SettlementDay total previsto de créditos do recebedor no dia
+type SettlementDay struct {
+	Date            string `json:"date"`
+	RecipientId     string `json:"recipient_id"`
+	Amount          int64  `json:"amount"`
+	Fee             int64  `json:"fee"`
+	AnticipationFee int64  `json:"anticipation_fee"`
+	NetAmount       int64  `json:"net_amount"`
+	Count           int    `json:"count"`
+}
+
+type SettlementDayPtr = *SettlementDay
+type SettlementCalendar = []SettlementDayPtr
+
+// NewSettlementCalendar agrupa os recebíveis por dia e recebedor, ordenado por data.
+// Recebíveis pagos ou antecipados já foram liquidados e não entram no calendário
+func NewSettlementCalendar(payables Payables) SettlementCalendar {
+	days := make(map[string]*SettlementDay)
+	calendar := SettlementCalendar{}
+
+	for _, it := range payables {
+		if it.Status == PayablePaid || it.Status == PayablePrepaid {
+			continue
+		}
+		key := it.GetPaymentDay() + ":" + it.RecipientId
+		day, ok := days[key]
+		if !ok {
+			day = &SettlementDay{Date: it.GetPaymentDay(), RecipientId: it.RecipientId}
+			days[key] = day
+			calendar = append(calendar, day)
+		}
+		day.Amount += it.Amount
+		day.Fee += it.Fee + it.FraudCoverageFee
+		day.AnticipationFee += it.AnticipationFee
+		day.NetAmount += it.GetNetAmount()
+		day.Count++
+	}
+
+	sort.SliceStable(calendar, func(i, j int) bool {
+		if calendar[i].Date == calendar[j].Date {
+			return calendar[i].RecipientId < calendar[j].RecipientId
+		}
+		return calendar[i].Date < calendar[j].Date
+	})
+
+	return calendar
 }
 
 type Transfer struct {
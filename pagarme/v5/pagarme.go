@@ -2,10 +2,12 @@ package v5
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/v2/either"
 	"io/ioutil"
 	"net/http"
@@ -251,6 +253,7 @@ func (this *Pagarme) onValidCustomer(customer CustomerPtr) bool {
 }
 
 func (this *Pagarme) onValidEntity(entity interface{}) bool {
+	this.resetValidation()
 	this.EntityValidatorResult, _ = this.EntityValidator.IsValid(entity, nil)
 
 	if this.EntityValidatorResult.HasError {
@@ -270,6 +273,13 @@ func (this *Pagarme) onValidationErrors() {
 	this.ValidationErrors = this.EntityValidator.GetValidationErrors(this.EntityValidatorResult)
 }
 
+// resetValidation limpa os erros da validação anterior, o mesmo cliente pode ser
+// usado em várias chamadas
+func (this *Pagarme) resetValidation() {
+	this.HasValidationError = false
+	this.ValidationErrors = nil
+}
+
 func (this *Pagarme) SetValidationError(key string, value string) {
 	this.HasValidationError = true
 	if this.ValidationErrors == nil {
@@ -278,7 +288,79 @@ func (this *Pagarme) SetValidationError(key string, value string) {
 	this.ValidationErrors[key] = value
 }
 
+// onValidSplits valida as regras de split de captura e estorno parcial
+func (this *Pagarme) onValidSplits(amount int64, splits []*Split) bool {
+	this.resetValidation()
+	valid := true
+
+	if amount < 0 {
+		this.SetValidationError("Amount", "Amount must be greater than zero")
+		valid = false
+	}
+
+	validateSplits(amount, splits, func(key string, message string) {
+		this.SetValidationError(key, message)
+		valid = false
+	})
+
+	return valid
+}
+
+// validateSplits valores flat devem somar exatamente o amount e percentuais devem somar 100.
+// Com amount zero (valor total) a soma flat não é verificada
+func validateSplits(amount int64, splits []*Split, setError func(key string, message string)) {
+
+	if len(splits) == 0 {
+		return
+	}
+
+	var total int64
+	var splitType OperationType
+	valid := true
+
+	for i, split := range splits {
+		if split == nil {
+			setError(fmt.Sprintf("Split[%v]", i), "Split is required")
+			valid = false
+			continue
+		}
+		if len(split.RecipientId) == 0 {
+			setError(fmt.Sprintf("Split[%v].RecipientId", i), "RecipientId is required")
+			valid = false
+		}
+		if split.Amount <= 0 {
+			setError(fmt.Sprintf("Split[%v].Amount", i), "Amount must be greater than zero")
+			valid = false
+		}
+		if split.Type != Flat && split.Type != Percentage {
+			setError(fmt.Sprintf("Split[%v].Type", i), "Type must be flat or percentage")
+			valid = false
+		} else if len(splitType) > 0 && split.Type != splitType {
+			setError("Split", "All splits must have the same type")
+			valid = false
+		}
+		splitType = split.Type
+		total += split.Amount
+	}
+
+	if !valid {
+		return
+	}
+
+	switch splitType {
+	case Percentage:
+		if total != 100 {
+			setError("Split", fmt.Sprintf("Split percentages must sum 100, got %v", total))
+		}
+	case Flat:
+		if amount > 0 && total != amount {
+			setError("Split", fmt.Sprintf("Split amounts must sum %v, got %v", amount, total))
+		}
+	}
+}
+
 func (this *Pagarme) processValidator() bool {
+	this.resetValidation()
 	val := this.EntityValidator.Validate()
 
 	switch val.(type) {
@@ -368,3 +450,27 @@ func checkEmpty[T any](label string, values ...string) (bool, *either.Either[*Er
 	}
 	return false, nil
 }
+
+const iterPageSize = 100
+
+// pageFetcher adapta uma listagem paginada para api.Paginate. A Pagar.me não informa
+// se existem mais páginas, então uma página incompleta encerra a iteração
+func pageFetcher[T any](page int, size int, list func(page int, size int) *either.Either[*ErrorResponse, *Success[[]T]]) api.PageFetcher[T] {
+
+	if page <= 0 {
+		page = 1
+	}
+
+	if size <= 0 {
+		size = iterPageSize
+	}
+
+	return func(ctx context.Context, index int) ([]T, bool, error) {
+		result := list(page+index, size)
+		if result.IsLeft() {
+			return nil, false, result.UnwrapLeft()
+		}
+		items := result.UnwrapRight().Data
+		return items, len(items) == size, nil
+	}
+}
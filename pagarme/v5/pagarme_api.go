@@ -11,6 +11,8 @@ type PagarmeApi struct {
 	Invoice      *PagarmeInvoice
 	Charge       *PagarmeCharge
 	Recipient    *PagarmeRecipient
+	Payable      *PagarmePayable
+	Hook         *PagarmeHook
 	ServiceRefererName ServiceRefererName
 }
 
@@ -39,6 +41,12 @@ func NewPagarmeApi(lang string, auth *Authentication, serviceRefererName Service
 	recipient := &PagarmeRecipient{}
 	recipient.Pagarme.init(lang, auth, serviceRefererName)
 
+	payable := &PagarmePayable{}
+	payable.Pagarme.init(lang, auth, serviceRefererName)
+
+	hook := &PagarmeHook{}
+	hook.Pagarme.init(lang, auth, serviceRefererName)
+
 	return &PagarmeApi{
 		Card:         card,
 		Customer:     customer,
@@ -48,6 +56,8 @@ func NewPagarmeApi(lang string, auth *Authentication, serviceRefererName Service
 		Invoice:      invoice,
 		Charge:       charge,
 		Recipient:    recipient,
+		Payable:      payable,
+		Hook:         hook,
 		ServiceRefererName: serviceRefererName,
 	}
 }
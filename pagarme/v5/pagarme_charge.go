@@ -1,9 +1,12 @@
 package v5
 
 import (
+	"context"
 	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/v2/either"
 	"github.com/mobilemindtec/go-utils/v2/maps"
+	"iter"
 	"time"
 )
 
@@ -21,18 +24,30 @@ func NewPagarmeCharge(lang string, auth *Authentication, serviceRefererName Serv
 }
 
 func (this *PagarmeCharge) Capture(id string, code string) *either.Either[*ErrorResponse, SuccessCharge] {
+	return this.CaptureWith(id, &ChargeCapture{Code: code})
+}
+
+// CaptureAmount captura parcial, com split opcional do valor capturado
+func (this *PagarmeCharge) CaptureAmount(id string, code string, amount int64, splits ...*Split) *either.Either[*ErrorResponse, SuccessCharge] {
+	return this.CaptureWith(id, &ChargeCapture{Code: code, Amount: amount, Split: splits})
+}
+
+func (this *PagarmeCharge) CaptureWith(id string, capture *ChargeCapture) *either.Either[*ErrorResponse, SuccessCharge] {
 
 	if empty, left := checkEmpty[SuccessCharge]("charge id", id); empty {
 		return left
 	}
 
-	uri := fmt.Sprintf("/charges/%v/capture", id)
+	if !this.onValidSplits(capture.Amount, capture.Split) {
+		return either.Left[*ErrorResponse, SuccessCharge](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
 
-	payload := maps.JSON("code", code)
+	uri := fmt.Sprintf("/charges/%v/capture", id)
 
 	return either.
 		MapIf(
-			this.post(uri, payload, createParser[Charge]()),
+			this.post(uri, capture, createParser[Charge]()),
 			func(e *either.Either[error, *Response]) *ErrorResponse {
 				return unwrapError(e.UnwrapLeft())
 			},
@@ -111,16 +126,35 @@ func (this *PagarmeCharge) UpdateDueDate(chargeId string, dueDate time.Time) *ei
 }
 
 func (this *PagarmeCharge) Cancel(chargeId string) *either.Either[*ErrorResponse, SuccessCharge] {
+	return this.CancelWith(chargeId, nil)
+}
+
+// CancelAmount estorno parcial, com split opcional do valor estornado
+func (this *PagarmeCharge) CancelAmount(chargeId string, amount int64, splits ...*Split) *either.Either[*ErrorResponse, SuccessCharge] {
+	return this.CancelWith(chargeId, &ChargeCancel{Amount: amount, Split: splits})
+}
+
+func (this *PagarmeCharge) CancelWith(chargeId string, cancel *ChargeCancel) *either.Either[*ErrorResponse, SuccessCharge] {
 
 	if empty, left := checkEmpty[SuccessCharge]("charge id", chargeId); empty {
 		return left
 	}
 
+	var payload interface{}
+
+	if cancel != nil {
+		if !this.onValidSplits(cancel.Amount, cancel.Split) {
+			return either.Left[*ErrorResponse, SuccessCharge](
+				NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+		}
+		payload = cancel
+	}
+
 	uri := fmt.Sprintf("/charges/%v", chargeId)
 
 	return either.
 		MapIf(
-			this.delete(uri, nil, createParser[Charge]()),
+			this.delete(uri, payload, createParser[Charge]()),
 			func(e *either.Either[error, *Response]) *ErrorResponse {
 				return unwrapError(e.UnwrapLeft())
 			},
@@ -191,6 +225,20 @@ func (this *PagarmeCharge) List(query *ChargeQuery) *either.Either[*ErrorRespons
 			})
 }
 
+// ListIter itera sobre todas as cobranças do filtro buscando as páginas sob demanda
+func (this *PagarmeCharge) ListIter(ctx context.Context, query *ChargeQuery, opts ...*api.PageOptions) iter.Seq2[ChargePtr, error] {
+
+	if query == nil {
+		query = NewChargeQuery()
+	}
+
+	return api.Paginate(ctx, pageFetcher(query.Page, query.Size, func(page int, size int) *either.Either[*ErrorResponse, SuccessCharges] {
+		pageQuery := *query
+		pageQuery.Page, pageQuery.Size = page, size
+		return this.List(&pageQuery)
+	}), opts...)
+}
+
 func (this *PagarmeCharge) ListPending(orderId string, size int) *either.Either[*ErrorResponse, SuccessCharges] {
 	query := NewChargeQuery()
 	query.OrderId = orderId
@@ -8,6 +8,9 @@ import (
 type SuccessCustomer = *Success[CustomerPtr]
 type SuccessCustomers = *Success[Customers]
 
+type SuccessAddress = *Success[AddressPtr]
+type SuccessAddresses = *Success[Addresses]
+
 type PagarmeCustomer struct {
 	Pagarme
 }
@@ -93,3 +96,118 @@ func (this *PagarmeCustomer) List(query *CustomerQuery) *either.Either[*ErrorRes
 				return NewSuccessSlice[Customers](e.UnwrapRight())
 			})
 }
+
+func (this *PagarmeCustomer) CreateAddress(customerId string, address AddressPtr) *either.Either[*ErrorResponse, SuccessAddress] {
+
+	if empty, left := checkEmpty[SuccessAddress]("customer id", customerId); empty {
+		return left
+	}
+
+	if !this.onValidAddress(address) {
+		return either.Left[*ErrorResponse, SuccessAddress](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/customers/%v/addresses", customerId)
+
+	return either.
+		MapIf(
+			this.post(uri, address, createParser[Address]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAddress {
+				return NewSuccess[AddressPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeCustomer) UpdateAddress(customerId string, address AddressPtr) *either.Either[*ErrorResponse, SuccessAddress] {
+
+	if empty, left := checkEmpty[SuccessAddress]("customer id and address id", customerId, address.Id); empty {
+		return left
+	}
+
+	if !this.onValidAddress(address) {
+		return either.Left[*ErrorResponse, SuccessAddress](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/customers/%v/addresses/%v", customerId, address.Id)
+
+	return either.
+		MapIf(
+			this.put(uri, address, createParser[Address]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAddress {
+				return NewSuccess[AddressPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeCustomer) GetAddress(customerId string, addressId string) *either.Either[*ErrorResponse, SuccessAddress] {
+
+	if empty, left := checkEmpty[SuccessAddress]("customer id and address id", customerId, addressId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/customers/%v/addresses/%v", customerId, addressId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Address]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAddress {
+				return NewSuccess[AddressPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeCustomer) ListAddresses(customerId string) *either.Either[*ErrorResponse, SuccessAddresses] {
+
+	if empty, left := checkEmpty[SuccessAddresses]("customer id", customerId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/customers/%v/addresses", customerId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Addresses]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAddresses {
+				return NewSuccessSlice[Addresses](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeCustomer) DeleteAddress(customerId string, addressId string) *either.Either[*ErrorResponse, SuccessBool] {
+
+	if empty, left := checkEmpty[SuccessBool]("customer id and address id", customerId, addressId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/customers/%v/addresses/%v", customerId, addressId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessBool {
+				return NewSuccessWithValue[bool](e.UnwrapRight(), true)
+			})
+}
+
+func (this *PagarmeCustomer) onValidAddress(address AddressPtr) bool {
+	this.resetValidation()
+	if address == nil {
+		this.SetValidationError("Address", "Address is required")
+		return false
+	}
+	this.EntityValidator.AddEntity(address)
+	return this.processValidator()
+}
@@ -0,0 +1,147 @@
+package v5
+
+import (
+	"fmt"
+	"github.com/mobilemindtec/go-utils/v2/either"
+	"time"
+)
+
+const hookPageSize = 100
+
+type SuccessHook = *Success[HookPtr]
+type SuccessHooks = *Success[Hooks]
+type SuccessHookSweep = *Success[HookSweepPtr]
+
+type PagarmeHook struct {
+	Pagarme
+}
+
+func NewPagarmeHook(lang string, auth *Authentication, serviceRefererName ServiceRefererName) *PagarmeHook {
+	p := &PagarmeHook{}
+	p.Pagarme.init(lang, auth, serviceRefererName)
+	return p
+}
+
+func (this *PagarmeHook) List(query *HookQuery) *either.Either[*ErrorResponse, SuccessHooks] {
+
+	if query == nil {
+		query = NewHookQuery()
+	}
+
+	uri := fmt.Sprintf("/hooks?%v", query.UrlQuery())
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Hooks]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessHooks {
+				return NewSuccessSlice[Hooks](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeHook) Get(hookId string) *either.Either[*ErrorResponse, SuccessHook] {
+
+	if empty, left := checkEmpty[SuccessHook]("hook id", hookId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/hooks/%v", hookId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Hook]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessHook {
+				return NewSuccess[HookPtr](e.UnwrapRight())
+			})
+}
+
+// Retry reenvia a entrega do hook
+func (this *PagarmeHook) Retry(hookId string) *either.Either[*ErrorResponse, SuccessHook] {
+
+	if empty, left := checkEmpty[SuccessHook]("hook id", hookId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/hooks/%v/retry", hookId)
+
+	return either.
+		MapIf(
+			this.post(uri, nil, createParser[Hook]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessHook {
+				return NewSuccess[HookPtr](e.UnwrapRight())
+			})
+}
+
+// RetryFailedForOrder reenvia todos os hooks com falha relacionados ao pedido
+func (this *PagarmeHook) RetryFailedForOrder(orderId string) *either.Either[*ErrorResponse, SuccessHookSweep] {
+
+	if empty, left := checkEmpty[SuccessHookSweep]("order id", orderId); empty {
+		return left
+	}
+
+	return this.retryFailed(NewHookQuery(), func(hook *Hook) bool {
+		return hook.GetOrderId() == orderId
+	})
+}
+
+// RetryFailedBetween reenvia todos os hooks com falha criados no período
+func (this *PagarmeHook) RetryFailedBetween(since time.Time, until time.Time) *either.Either[*ErrorResponse, SuccessHookSweep] {
+	return this.retryFailed(NewHookQuery().WithCreated(since, until), func(hook *Hook) bool {
+		return true
+	})
+}
+
+func (this *PagarmeHook) retryFailed(query *HookQuery, filter func(*Hook) bool) *either.Either[*ErrorResponse, SuccessHookSweep] {
+
+	query.Status = WebhookFailed
+	query.Size = hookPageSize
+	query.Page = 1
+
+	failed := Hooks{}
+
+	// busca todas as páginas antes de reenviar, pois o reenvio altera o status
+	for {
+		result := this.List(query)
+
+		if result.IsLeft() {
+			return either.Left[*ErrorResponse, SuccessHookSweep](result.UnwrapLeft())
+		}
+
+		page := result.UnwrapRight().Data
+		for _, hook := range page {
+			if hook.IsFailed() && filter(hook) {
+				failed = append(failed, hook)
+			}
+		}
+
+		if len(page) < query.Size {
+			break
+		}
+
+		query.Page++
+	}
+
+	sweep := &HookSweep{
+		Retried: Hooks{},
+		Errors:  map[string]*ErrorResponse{},
+	}
+
+	for _, hook := range failed {
+		result := this.Retry(hook.Id)
+		if result.IsLeft() {
+			sweep.Errors[hook.Id] = result.UnwrapLeft()
+		} else {
+			sweep.Retried = append(sweep.Retried, result.UnwrapRight().Data)
+		}
+	}
+
+	return either.Right[*ErrorResponse, SuccessHookSweep](&Success[HookSweepPtr]{Data: sweep})
+}
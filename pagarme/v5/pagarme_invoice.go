@@ -1,8 +1,11 @@
 package v5
 
 import (
+	"context"
 	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/v2/either"
+	"iter"
 )
 
 type SuccessInvoice = *Success[InvoicePtr]
@@ -51,6 +54,20 @@ func (this *PagarmeInvoice) List(query *InvoiceQuery) *either.Either[*ErrorRespo
 			})
 }
 
+// ListIter itera sobre todas as faturas do filtro buscando as páginas sob demanda
+func (this *PagarmeInvoice) ListIter(ctx context.Context, query *InvoiceQuery, opts ...*api.PageOptions) iter.Seq2[InvoicePtr, error] {
+
+	if query == nil {
+		query = NewInvoiceQuery()
+	}
+
+	return api.Paginate(ctx, pageFetcher(query.Page, query.Size, func(page int, size int) *either.Either[*ErrorResponse, SuccessInvoices] {
+		pageQuery := *query
+		pageQuery.Page, pageQuery.Size = page, size
+		return this.List(&pageQuery)
+	}), opts...)
+}
+
 func (this *PagarmeInvoice) Cancel(id string) *either.Either[*ErrorResponse, SuccessBool] {
 	
 	if len(id) == 0 {
@@ -1,14 +1,18 @@
 package v5
 
 import (
+	"context"
 	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/beego/validator"
 	"github.com/mobilemindtec/go-utils/v2/either"
+	"iter"
 	"reflect"
 )
 
 type SuccessOrder = *Success[OrderPtr]
 type SuccessOrders = *Success[Orders]
+type SuccessOrderItem = *Success[OrderItemPtr]
 
 type PagarmeOrder struct {
 	Pagarme
@@ -38,6 +42,21 @@ func (this *PagarmeOrder) Create(order OrderPtr) *either.Either[*ErrorResponse,
 			})
 }
 
+// CreateCheckout cria o pedido com link de pagamento, a url fica em Order.GetCheckoutUrl
+func (this *PagarmeOrder) CreateCheckout(order OrderPtr) *either.Either[*ErrorResponse, SuccessOrder] {
+
+	for _, it := range order.Payments {
+		if it.PaymentMethod != MethodCheckout {
+			return either.Left[*ErrorResponse, SuccessOrder](
+				NewErrorResponse("checkout order accepts only checkout payments"))
+		}
+	}
+
+	order.Closed = true
+
+	return this.Create(order)
+}
+
 func (this *PagarmeOrder) Get(orderId string) *either.Either[*ErrorResponse, SuccessOrder] {
 
 	if empty, left := checkEmpty[SuccessOrder]("order id", orderId); empty {
@@ -72,17 +91,384 @@ func (this *PagarmeOrder) List(query *OrderQuery) *either.Either[*ErrorResponse,
 			})
 }
 
+// ListIter itera sobre todos os pedidos do filtro buscando as páginas sob demanda
+func (this *PagarmeOrder) ListIter(ctx context.Context, query *OrderQuery, opts ...*api.PageOptions) iter.Seq2[OrderPtr, error] {
+
+	if query == nil {
+		query = NewOrderQuery()
+	}
+
+	return api.Paginate(ctx, pageFetcher(query.Page, query.Size, func(page int, size int) *either.Either[*ErrorResponse, SuccessOrders] {
+		pageQuery := *query
+		pageQuery.Page, pageQuery.Size = page, size
+		return this.List(&pageQuery)
+	}), opts...)
+}
+
+// Close fecha um pedido aberto com status paid, canceled ou failed
+func (this *PagarmeOrder) Close(orderId string, status OrderStatus) *either.Either[*ErrorResponse, SuccessOrder] {
+
+	if empty, left := checkEmpty[SuccessOrder]("order id and status", orderId, string(status)); empty {
+		return left
+	}
+
+	switch status {
+	case OrderPaid, OrderCanceled, OrderFailed:
+	default:
+		return either.Left[*ErrorResponse, SuccessOrder](
+			NewErrorResponse(fmt.Sprintf("invalid order close status %v", status)))
+	}
+
+	uri := fmt.Sprintf("/orders/%v/closed", orderId)
+
+	return either.
+		MapIf(
+			this.patch(uri, &OrderClose{Status: status}, createParser[Order]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessOrder {
+				return NewSuccess[OrderPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeOrder) Cancel(orderId string) *either.Either[*ErrorResponse, SuccessOrder] {
+	return this.Close(orderId, OrderCanceled)
+}
+
+func (this *PagarmeOrder) AddItem(orderId string, item OrderItemPtr) *either.Either[*ErrorResponse, SuccessOrderItem] {
+
+	if empty, left := checkEmpty[SuccessOrderItem]("order id", orderId); empty {
+		return left
+	}
+
+	if !this.onValidOrderItem(item) {
+		return either.Left[*ErrorResponse, SuccessOrderItem](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/orders/%v/items", orderId)
+
+	return either.
+		MapIf(
+			this.post(uri, item, createParser[OrderItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessOrderItem {
+				return NewSuccess[OrderItemPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeOrder) GetItem(orderId string, itemId string) *either.Either[*ErrorResponse, SuccessOrderItem] {
+
+	if empty, left := checkEmpty[SuccessOrderItem]("order id and item id", orderId, itemId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/orders/%v/items/%v", orderId, itemId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[OrderItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessOrderItem {
+				return NewSuccess[OrderItemPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeOrder) UpdateItem(orderId string, item OrderItemPtr) *either.Either[*ErrorResponse, SuccessOrderItem] {
+
+	if empty, left := checkEmpty[SuccessOrderItem]("order id and item id", orderId, item.Id); empty {
+		return left
+	}
+
+	if !this.onValidOrderItem(item) {
+		return either.Left[*ErrorResponse, SuccessOrderItem](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/orders/%v/items/%v", orderId, item.Id)
+
+	return either.
+		MapIf(
+			this.put(uri, item, createParser[OrderItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessOrderItem {
+				return NewSuccess[OrderItemPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeOrder) RemoveItem(orderId string, itemId string) *either.Either[*ErrorResponse, SuccessBool] {
+
+	if empty, left := checkEmpty[SuccessBool]("order id and item id", orderId, itemId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/orders/%v/items/%v", orderId, itemId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessBool {
+				return NewSuccessWithValue[bool](e.UnwrapRight(), true)
+			})
+}
+
+// RemoveItems remove todos os itens do pedido
+func (this *PagarmeOrder) RemoveItems(orderId string) *either.Either[*ErrorResponse, SuccessBool] {
+
+	if empty, left := checkEmpty[SuccessBool]("order id", orderId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/orders/%v/items", orderId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessBool {
+				return NewSuccessWithValue[bool](e.UnwrapRight(), true)
+			})
+}
+
+// AddCharge inclui uma cobrança em um pedido aberto
+func (this *PagarmeOrder) AddCharge(orderId string, payment *Payment) *either.Either[*ErrorResponse, SuccessCharge] {
+
+	if empty, left := checkEmpty[SuccessCharge]("order id", orderId); empty {
+		return left
+	}
+
+	if payment == nil || payment.Amount <= 0 {
+		return either.Left[*ErrorResponse, SuccessCharge](
+			NewErrorResponse("payment with amount is required"))
+	}
+
+	if !this.onValidSplits(payment.Amount, payment.Split) {
+		return either.Left[*ErrorResponse, SuccessCharge](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	charge := &OrderCharge{
+		OrderId: orderId,
+		Amount:  payment.Amount,
+		Payment: payment,
+	}
+
+	return either.
+		MapIf(
+			this.post("/charges", charge, createParser[Charge]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessCharge {
+				return NewSuccess[ChargePtr](e.UnwrapRight())
+			})
+}
+
+// CaptureAll captura todas as cobranças de cartão autorizadas do pedido. Cobranças já pagas
+// ou de outros meios de pagamento (pix, boleto) são ignoradas. Se alguma captura falhar,
+// as cobranças já capturadas e as ainda autorizadas são canceladas, ficando tudo ou nada.
+// Cancelamentos que falharem são retornados em ErrorResponse.Errors por id da cobrança
+func (this *PagarmeOrder) CaptureAll(orderId string) *either.Either[*ErrorResponse, SuccessOrder] {
+
+	result := this.Get(orderId)
+
+	if result.IsLeft() {
+		return result
+	}
+
+	order := result.UnwrapRight().Data
+
+	for _, it := range order.Charges {
+		if it.IsCard() && !it.IsWaitingCapture() && it.Status != ChargePaid {
+			return either.Left[*ErrorResponse, SuccessOrder](
+				NewErrorResponse(fmt.Sprintf("charge %v with status %v can't be captured", it.Id, it.Status)))
+		}
+	}
+
+	pending := order.GetChargesWaitingCapture()
+
+	if len(pending) == 0 {
+		return result
+	}
+
+	charges := this.chargeService()
+
+	for i, it := range pending {
+		capture := charges.Capture(it.Id, it.Code)
+
+		if capture.IsLeft() {
+
+			// captured charges are refunded and the failed and next ones are voided,
+			// so no card hold is kept
+			failures := this.cancelCharges(charges, pending)
+
+			err := capture.UnwrapLeft()
+			err.Message = fmt.Sprintf("capture of charge %v failed after %v captured charges, %v of %v charges reverted: %v",
+				it.Id, i, len(pending)-len(failures), len(pending), err.Message)
+			err.Errors = mergeChargeFailures(err.Errors, failures)
+			return either.Left[*ErrorResponse, SuccessOrder](err)
+		}
+	}
+
+	return this.Get(orderId)
+}
+
+// CancelAll cancela todas as cobranças do pedido. Cobranças já canceladas ou com falha
+// são ignoradas, assim uma cobrança recusada não impede o estorno das demais. Todas as
+// cobranças são tentadas e as que falharem são retornadas em ErrorResponse.Errors
+func (this *PagarmeOrder) CancelAll(orderId string) *either.Either[*ErrorResponse, SuccessOrder] {
+
+	result := this.Get(orderId)
+
+	if result.IsLeft() {
+		return result
+	}
+
+	order := result.UnwrapRight().Data
+	cancelable := []*Charge{}
+
+	for _, it := range order.Charges {
+		if it.IsCancelable() {
+			cancelable = append(cancelable, it)
+		}
+	}
+
+	if len(cancelable) == 0 {
+		return result
+	}
+
+	if failures := this.cancelCharges(this.chargeService(), cancelable); len(failures) > 0 {
+		return either.Left[*ErrorResponse, SuccessOrder](
+			NewErrorResponseWithErrors(
+				fmt.Sprintf("cancel failed on %v of %v charges", len(failures), len(cancelable)), failures))
+	}
+
+	return this.Get(orderId)
+}
+
+// cancelCharges cancela as cobranças e retorna as mensagens de erro por id da cobrança
+func (this *PagarmeOrder) cancelCharges(charges *PagarmeCharge, list []*Charge) map[string][]string {
+	failures := map[string][]string{}
+	for _, it := range list {
+		if cancel := charges.Cancel(it.Id); cancel.IsLeft() {
+			this.Log("cancel of charge %v failed: %v", it.Id, cancel.UnwrapLeft().Message)
+			failures[it.Id] = []string{cancel.UnwrapLeft().Message}
+		}
+	}
+	return failures
+}
+
+func mergeChargeFailures(errors map[string][]string, failures map[string][]string) map[string][]string {
+	if len(failures) == 0 {
+		return errors
+	}
+	if errors == nil {
+		errors = map[string][]string{}
+	}
+	for k, v := range failures {
+		errors[k] = append(errors[k], v...)
+	}
+	return errors
+}
+
+func (this *PagarmeOrder) chargeService() *PagarmeCharge {
+	charges := NewPagarmeCharge(this.Lang, this.Auth, this.ServiceRefererName)
+	charges.SetDebug(this.Debug)
+	return charges
+}
+
+func (this *Pagarme) onValidOrderItem(item *OrderItem) bool {
+
+	this.EntityValidator.AddEntity(item)
+
+	this.EntityValidator.AddValidationForType(
+		reflect.TypeOf(item), func(entity interface{}, validator *validator.Validation) {
+			p := entity.(*OrderItem)
+
+			if p.Amount <= 0 {
+				validator.SetError("Amount", "Amount must be bigger than zero")
+			}
+
+			if p.Quantity <= 0 {
+				validator.SetError("Quantity", "Quantity must be bigger than zero")
+			}
+
+			if len(p.Description) == 0 {
+				validator.SetError("Description", "Description is required")
+			}
+		})
+
+	return this.processValidator()
+}
+
+func (this *Pagarme) addPaymentCardValidation(card *Card, validationType CardValidationType) {
+
+	if card == nil {
+		return
+	}
+
+	this.EntityValidator.AddEntity(card)
+
+	if card.BillingAddress != nil {
+		this.EntityValidator.AddEntity(card.BillingAddress)
+	}
+
+	this.EntityValidator.AddValidationForType(
+		reflect.TypeOf(card), cardValidator(validationType))
+}
+
+// validateAuthentication 3DS pela adquirente exige SuccessUrl, por MPI externo exige Cavv, Eci e TransactionId
+func validateAuthentication(authentication *PaymentAuthentication, setError func(key string, message string)) {
+
+	if authentication == nil {
+		return
+	}
+
+	if authentication.Type != AuthenticationThreeDSecure || authentication.ThreeDSecure == nil {
+		setError("Authentication", "Authentication type threed_secure with ThreeDSecure object is required")
+		return
+	}
+
+	tds := authentication.ThreeDSecure
+
+	switch tds.Mpi {
+	case MpiAcquirer:
+		if len(tds.SuccessUrl) == 0 {
+			setError("ThreeDSecure", "SuccessUrl is required to acquirer mpi")
+		}
+	case MpiThirdParty:
+		if len(tds.Cavv) == 0 || len(tds.Eci) == 0 || len(tds.TransactionId) == 0 {
+			setError("ThreeDSecure", "Cavv, Eci and TransactionId are required to third_party mpi")
+		}
+	default:
+		setError("ThreeDSecure", "Mpi is required acquirer or third_party")
+	}
+}
+
 func (this *Pagarme) onValidOrder(order *Order) bool {
 
 	this.EntityValidator.AddValidationForType(
 		reflect.TypeOf(order), func(entity interface{}, validator *validator.Validation) {
 			p := entity.(*Order)
 
-			if p.Payments == nil || len(p.Payments) == 0 {
+			// somente pedido aberto explicitamente (NewOpenOrder) pode ser criado sem pagamentos,
+			// as cobranças são adicionadas depois com AddCharge
+			if !p.IsOpenRequested() && len(p.Payments) == 0 {
 				validator.SetError("Payments", "Payments array is required")
 			}
 
-
 			if len(p.Items) == 0 {
 				validator.SetError("Items", "Items is required")
 			}
@@ -93,11 +479,24 @@ func (this *Pagarme) onValidOrder(order *Order) bool {
 				}
 			}
 
+			if p.Shipping != nil && p.Shipping.Address == nil && len(p.Shipping.AddressId) == 0 {
+				validator.SetError("Shipping", "Shipping Address or AddressId is required")
+			}
+
+			// múltiplos pagamentos devem fechar o total do pedido
+			if p.IsMultiPayment() && !p.IsPaymentsAmountValid() {
+				validator.SetError("Payments", fmt.Sprintf("Payments amount %v must be equal to order amount %v",
+					p.GetPaymentsAmount(), p.GetItemsAmount()))
+			}
+
 		})
 
-	
 	this.EntityValidator.AddEntity(order)
 
+	if order.Shipping != nil && order.Shipping.Address != nil {
+		this.EntityValidator.AddEntity(order.Shipping.Address)
+	}
+
 	if order.Payments != nil {
 		for _, it := range order.Payments {
 
@@ -112,10 +511,33 @@ func (this *Pagarme) onValidOrder(order *Order) bool {
 						validator.SetError("Amount", "Amount is required")
 					}
 
+					if p.IsCard() && p.GetCard() == nil && !p.HasCardIdOrToken() {
+						validator.SetError("Card", "Card, CardId or CardToken is required")
+					}
+
 					switch p.PaymentMethod {
 					case MethodCreditCard:
 						if p.CreditCard == nil {
 							validator.SetError("Payment", "CreditCard object is required")
+						} else {
+							validateAuthentication(p.CreditCard.Authentication, func(key string, message string) {
+								validator.SetError(key, message)
+							})
+						}
+					case MethodDebitCard:
+						if p.DebitCard == nil {
+							validator.SetError("Payment", "DebitCard object is required")
+						} else {
+							validateAuthentication(p.DebitCard.Authentication, func(key string, message string) {
+								validator.SetError(key, message)
+							})
+						}
+					case MethodVoucher:
+						if p.Voucher == nil {
+							validator.SetError("Payment", "Voucher object is required")
+						} else if len(p.Voucher.CardId) == 0 && len(p.Voucher.CardToken) == 0 &&
+							p.Voucher.Card != nil && len(p.Voucher.Card.HolderDocument) == 0 {
+							validator.SetError("HolderDocument", "HolderDocument is required to voucher")
 						}
 					case MethodBoleto:
 						if p.Boleto == nil {
@@ -125,9 +547,23 @@ func (this *Pagarme) onValidOrder(order *Order) bool {
 						if p.Pix == nil {
 							validator.SetError("Payment", "Pix object is required")
 						}
+					case MethodCheckout:
+						if p.Checkout == nil || len(p.Checkout.AcceptedPaymentMethods) == 0 {
+							validator.SetError("Payment", "Checkout object with AcceptedPaymentMethods is required")
+						} else if p.Checkout.IsAccepted(MethodCreditCard) && p.Checkout.CreditCard != nil {
+							for _, it := range p.Checkout.CreditCard.Installments {
+								if it.Number < 1 || it.Total < p.Amount {
+									validator.SetError("Installments", "Installment number and total must be bigger than amount")
+								}
+							}
+						}
 					default:
 						validator.SetError("Payment", "PaymentMethod is required")
 					}
+
+					validateSplits(p.Amount, p.Split, func(key string, message string) {
+						validator.SetError(key, message)
+					})
 				})
 
 			switch it.PaymentMethod {
@@ -152,7 +588,9 @@ func (this *Pagarme) onValidOrder(order *Order) bool {
 						this.EntityValidator.AddValidationForType(
 							reflect.TypeOf(it.CreditCard), func(entity interface{}, validator *validator.Validation) {
 
-								if it.CreditCard.Card == nil {
+								card := entity.(*CreditCard)
+
+								if len(card.CardId) == 0 && len(card.CardToken) == 0 && card.Card == nil {
 									validator.SetError("Card", "Card is required")
 								}
 							})
@@ -160,6 +598,24 @@ func (this *Pagarme) onValidOrder(order *Order) bool {
 
 
 
+				}
+			case MethodDebitCard:
+				if it.DebitCard != nil {
+
+					this.EntityValidator.AddEntity(it.DebitCard)
+
+					if len(it.DebitCard.CardId) == 0 && len(it.DebitCard.CardToken) == 0 {
+						this.addPaymentCardValidation(it.DebitCard.Card, ValidateCardCreate)
+					}
+				}
+			case MethodVoucher:
+				if it.Voucher != nil {
+
+					this.EntityValidator.AddEntity(it.Voucher)
+
+					if len(it.Voucher.CardId) == 0 && len(it.Voucher.CardToken) == 0 {
+						this.addPaymentCardValidation(it.Voucher.Card, ValidateCardCreate)
+					}
 				}
 			case MethodBoleto:
 				if it.Boleto != nil {
@@ -0,0 +1,105 @@
+package v5
+
+import (
+	"fmt"
+	"github.com/mobilemindtec/go-utils/v2/either"
+)
+
+// payablePageSize tamanho máximo de página aceito pela API
+const payablePageSize = 100
+
+type SuccessPayable = *Success[PayablePtr]
+type SuccessPayables = *Success[Payables]
+type SuccessSettlementCalendar = *Success[SettlementCalendar]
+
+type PagarmePayable struct {
+	Pagarme
+}
+
+func NewPagarmePayable(lang string, auth *Authentication, serviceRefererName ServiceRefererName) *PagarmePayable {
+	p := &PagarmePayable{}
+	p.Pagarme.init(lang, auth, serviceRefererName)
+	return p
+}
+
+func (this *PagarmePayable) List(query *PayableQuery) *either.Either[*ErrorResponse, SuccessPayables] {
+
+	if query == nil {
+		query = NewPayableQuery()
+	}
+
+	uri := fmt.Sprintf("/payables?%v", query.UrlQuery())
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Payables]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessPayables {
+				return NewSuccessSlice[Payables](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmePayable) Get(payableId string) *either.Either[*ErrorResponse, SuccessPayable] {
+
+	if empty, left := checkEmpty[SuccessPayable]("payable id", payableId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/payables/%v", payableId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Payable]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessPayable {
+				return NewSuccess[PayablePtr](e.UnwrapRight())
+			})
+}
+
+// SettlementCalendar busca todas as páginas de recebíveis do filtro e totaliza
+// os créditos previstos por dia e recebedor. Sem status no filtro busca somente
+// os recebíveis aguardando liquidação (waiting_funds)
+func (this *PagarmePayable) SettlementCalendar(query *PayableQuery) *either.Either[*ErrorResponse, SuccessSettlementCalendar] {
+
+	if query == nil {
+		query = NewPayableQuery()
+	}
+
+	pageQuery := *query
+	if len(pageQuery.Status) == 0 {
+		pageQuery.Status = PayableWaitingFunds
+	}
+	// acima do limite a API retorna páginas menores que Size e a paginação pararia na primeira
+	if pageQuery.Size <= 0 || pageQuery.Size > payablePageSize {
+		pageQuery.Size = payablePageSize
+	}
+	if pageQuery.Page <= 0 {
+		pageQuery.Page = 1
+	}
+
+	payables := Payables{}
+
+	for {
+		result := this.List(&pageQuery)
+
+		if result.IsLeft() {
+			return either.Left[*ErrorResponse, SuccessSettlementCalendar](result.UnwrapLeft())
+		}
+
+		page := result.UnwrapRight().Data
+		payables = append(payables, page...)
+
+		if len(page) < pageQuery.Size {
+			break
+		}
+
+		pageQuery.Page++
+	}
+
+	return either.Right[*ErrorResponse, SuccessSettlementCalendar](
+		&Success[SettlementCalendar]{Data: NewSettlementCalendar(payables)})
+}
@@ -11,6 +11,8 @@ import (
 type SuccessPlan = *Success[PlanPtr]
 type SuccessPlans = *Success[Plans]
 
+type SuccessPlanItem = *Success[PlanItemPtr]
+
 type PagarmePlan struct {
 	Pagarme
 }
@@ -116,6 +118,93 @@ func (this *PagarmePlan) Delete(id string) *either.Either[*ErrorResponse, Succes
 			})
 }
 
+func (this *PagarmePlan) AddItem(planId string, item PlanItemPtr) *either.Either[*ErrorResponse, SuccessPlanItem] {
+
+	if empty, left := checkEmpty[SuccessPlanItem]("plan id", planId); empty {
+		return left
+	}
+
+	if !this.validateItem(item) {
+		return either.Left[*ErrorResponse, SuccessPlanItem](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/plans/%v/items", planId)
+
+	return either.
+		MapIf(
+			this.post(uri, item, createParser[PlanItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessPlanItem {
+				return NewSuccess[PlanItemPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmePlan) GetItem(planId string, itemId string) *either.Either[*ErrorResponse, SuccessPlanItem] {
+
+	if empty, left := checkEmpty[SuccessPlanItem]("plan id and plan item id", planId, itemId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/plans/%v/items/%v", planId, itemId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[PlanItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessPlanItem {
+				return NewSuccess[PlanItemPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmePlan) UpdateItem(planId string, item PlanItemPtr) *either.Either[*ErrorResponse, SuccessPlanItem] {
+
+	if empty, left := checkEmpty[SuccessPlanItem]("plan id and plan item id", planId, item.Id); empty {
+		return left
+	}
+
+	if !this.validateItem(item) {
+		return either.Left[*ErrorResponse, SuccessPlanItem](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/plans/%v/items/%v", planId, item.Id)
+
+	return either.
+		MapIf(
+			this.put(uri, item, createParser[PlanItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessPlanItem {
+				return NewSuccess[PlanItemPtr](e.UnwrapRight())
+			})
+}
+
+// RemoveItem remove o item do plano, assinaturas existentes não são alteradas
+func (this *PagarmePlan) RemoveItem(planId string, itemId string) *either.Either[*ErrorResponse, SuccessPlanItem] {
+
+	if empty, left := checkEmpty[SuccessPlanItem]("plan id and plan item id", planId, itemId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/plans/%v/items/%v", planId, itemId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil, createParser[PlanItem]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessPlanItem {
+				return NewSuccess[PlanItemPtr](e.UnwrapRight())
+			})
+}
+
 func (this *PagarmePlan) validate(plan *Plan) bool {
 	this.EntityValidator.AddEntity(plan)
 
@@ -148,3 +237,25 @@ func planValidator(entity interface{}, validator *validator.Validation) {
 		}
 	}
 }
+
+func (this *PagarmePlan) validateItem(item *PlanItem) bool {
+	this.EntityValidator.AddEntity(item)
+	this.EntityValidator.AddValidationForType(reflect.TypeOf(item), planItemValidator)
+	return this.processValidator()
+}
+
+func planItemValidator(entity interface{}, validator *validator.Validation) {
+	c := entity.(*PlanItem)
+
+	if len(c.Name) == 0 {
+		validator.SetError("Name", "Name is required")
+	}
+
+	if c.Quantity < 0 {
+		validator.SetError("Quantity", "Quantity can't be negative")
+	}
+
+	validatePricingScheme(c.PricingScheme, func(key string, message string) {
+		validator.SetError(key, message)
+	})
+}
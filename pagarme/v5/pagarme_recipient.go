@@ -1,15 +1,20 @@
 package v5
 
 import (
+	"context"
 	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/beego/validator"
 	"github.com/mobilemindtec/go-utils/v2/either"
 	"github.com/mobilemindtec/go-utils/v2/maps"
+	"iter"
 	"reflect"
+	"time"
 )
 
 type SuccessRecipient = *Success[RecipientPtr]
 type SuccessRecipients = *Success[Recipients]
+type SuccessKycLink = *Success[KycLinkPtr]
 
 type SuccessBalance = *Success[BalancePtr]
 type SuccessBalanceOperations = *Success[BalanceOperations]
@@ -17,6 +22,14 @@ type SuccessBalanceOperations = *Success[BalanceOperations]
 type SuccessTransfer = *Success[TransferPtr]
 type SuccessTransfers = *Success[Transfers]
 
+type SuccessAnticipation = *Success[AnticipationPtr]
+type SuccessAnticipations = *Success[Anticipations]
+type SuccessAnticipationLimits = *Success[AnticipationLimitsPtr]
+type SuccessAnticipationEstimate = *Success[AnticipationEstimatePtr]
+
+// PagarmeRecipient recebedores, saldo, transferências e antecipações. Antecipações
+// podem ser consultadas e criadas, mas não canceladas: a API v5 não expõe o
+// cancelamento (existente na v4), que deve ser feito pela dashboard
 type PagarmeRecipient struct {
 	Pagarme
 }
@@ -45,6 +58,45 @@ func (this *PagarmeRecipient) Create(recipient *Recipient) *either.Either[*Error
 			})
 }
 
+// Register cria o recebedor com os dados cadastrais (register_information) exigidos para KYC
+func (this *PagarmeRecipient) Register(recipient *RecipientRegister) *either.Either[*ErrorResponse, SuccessRecipient] {
+
+	if !this.validateRegister(recipient) {
+		return either.Left[*ErrorResponse, SuccessRecipient](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	return either.
+		MapIf(
+			this.post("/recipients", recipient, createParser[Recipient]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessRecipient {
+				return NewSuccess[RecipientPtr](e.UnwrapRight())
+			})
+}
+
+// KycLink gera o link de verificação de identidade para o recebedor
+func (this *PagarmeRecipient) KycLink(recipientId string) *either.Either[*ErrorResponse, SuccessKycLink] {
+
+	if empty, left := checkEmpty[SuccessKycLink]("recipiente id", recipientId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/recipients/%v/kyc_link", recipientId)
+
+	return either.
+		MapIf(
+			this.post(uri, nil, createParser[KycLink]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessKycLink {
+				return NewSuccess[KycLinkPtr](e.UnwrapRight())
+			})
+}
+
 func (this *PagarmeRecipient) Update(id string, recipient *RecipientUpdate) *either.Either[*ErrorResponse, SuccessRecipient] {
 
 	if empty, left := checkEmpty[SuccessRecipient]("recipiente id", id); empty {
@@ -168,7 +220,13 @@ func (this *PagarmeRecipient) BalanceOperations(recipientId string, query *Balan
 		return left
 	}
 
-	uri := fmt.Sprintf("/balance/operations?%b", query.UrlQuery())
+	if query == nil {
+		query = NewBalanceQuery()
+	}
+
+	query.RecipientId = recipientId
+
+	uri := fmt.Sprintf("/balance/operations?%v", query.UrlQuery())
 
 	return either.
 		MapIf(
@@ -181,6 +239,20 @@ func (this *PagarmeRecipient) BalanceOperations(recipientId string, query *Balan
 			})
 }
 
+// BalanceOperationsIter itera sobre todas as operações de saldo do recebedor buscando as páginas sob demanda
+func (this *PagarmeRecipient) BalanceOperationsIter(ctx context.Context, recipientId string, query *BalanceQuery, opts ...*api.PageOptions) iter.Seq2[BalanceOperationPtr, error] {
+
+	if query == nil {
+		query = NewBalanceQuery()
+	}
+
+	return api.Paginate(ctx, pageFetcher(query.Page, query.Size, func(page int, size int) *either.Either[*ErrorResponse, SuccessBalanceOperations] {
+		pageQuery := *query
+		pageQuery.Page, pageQuery.Size = page, size
+		return this.BalanceOperations(recipientId, &pageQuery)
+	}), opts...)
+}
+
 func (this *PagarmeRecipient) CreateTransfer(recipientId string, amount int64) *either.Either[*ErrorResponse, SuccessTransfer] {
 
 	if empty, left := checkEmpty[SuccessTransfer]("recipiente id", recipientId); empty {
@@ -240,6 +312,160 @@ func (this *PagarmeRecipient) GetTransfer(recipientId string, transferId string)
 			})
 }
 
+func (this *PagarmeRecipient) AnticipationLimits(recipientId string, timeframe AnticipationTimeframe, paymentDate time.Time) *either.Either[*ErrorResponse, SuccessAnticipationLimits] {
+
+	if empty, left := checkEmpty[SuccessAnticipationLimits]("recipiente id", recipientId); empty {
+		return left
+	}
+
+	if !this.onValidAnticipation(1, timeframe, paymentDate) {
+		return either.Left[*ErrorResponse, SuccessAnticipationLimits](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/recipients/%v/anticipation_limits?timeframe=%v&payment_date=%v",
+		recipientId, timeframe, paymentDate.Format(DateLayout))
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[AnticipationLimits]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAnticipationLimits {
+				return NewSuccess[AnticipationLimitsPtr](e.UnwrapRight())
+			})
+}
+
+// EstimateAnticipation consulta os limites e estima as taxas proporcionais ao valor máximo.
+// A API v5 não possui simulação, o valor é uma aproximação e as taxas reais são as
+// retornadas por CreateAnticipation
+func (this *PagarmeRecipient) EstimateAnticipation(recipientId string, anticipation AnticipationPtr) *either.Either[*ErrorResponse, SuccessAnticipationEstimate] {
+
+	paymentDate, _ := time.Parse(DateLayout, anticipation.PaymentDate)
+
+	limitsResult := this.AnticipationLimits(recipientId, anticipation.Timeframe, paymentDate)
+
+	if limitsResult.IsLeft() {
+		return either.Left[*ErrorResponse, SuccessAnticipationEstimate](limitsResult.UnwrapLeft())
+	}
+
+	success := limitsResult.UnwrapRight()
+	limits := success.Data
+
+	if !limits.Allows(anticipation.Amount) {
+		return either.Left[*ErrorResponse, SuccessAnticipationEstimate](
+			NewErrorResponse(fmt.Sprintf("anticipation amount %v out of limits", anticipation.Amount)))
+	}
+
+	estimate := &AnticipationEstimate{
+		Amount:      anticipation.Amount,
+		Timeframe:   anticipation.Timeframe,
+		PaymentDate: anticipation.PaymentDate,
+		Limits:      limits,
+	}
+
+	if limits.Max.Amount > 0 {
+		estimate.AnticipationFee = limits.Max.AnticipationFee * anticipation.Amount / limits.Max.Amount
+		estimate.Fee = limits.Max.Fee * anticipation.Amount / limits.Max.Amount
+	}
+
+	estimate.NetAmount = estimate.Amount - estimate.AnticipationFee - estimate.Fee
+
+	return either.Right[*ErrorResponse, SuccessAnticipationEstimate](
+		&Success[AnticipationEstimatePtr]{Data: estimate, RawResponse: success.RawResponse, RawRequest: success.RawRequest})
+}
+
+func (this *PagarmeRecipient) CreateAnticipation(recipientId string, anticipation AnticipationPtr) *either.Either[*ErrorResponse, SuccessAnticipation] {
+
+	if empty, left := checkEmpty[SuccessAnticipation]("recipiente id", recipientId); empty {
+		return left
+	}
+
+	paymentDate, _ := time.Parse(DateLayout, anticipation.PaymentDate)
+
+	if !this.onValidAnticipation(anticipation.Amount, anticipation.Timeframe, paymentDate) {
+		return either.Left[*ErrorResponse, SuccessAnticipation](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/recipients/%v/anticipations", recipientId)
+
+	return either.
+		MapIf(
+			this.post(uri, anticipation, createParser[Anticipation]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAnticipation {
+				return NewSuccess[AnticipationPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeRecipient) ListAnticipations(recipientId string, query *AnticipationQuery) *either.Either[*ErrorResponse, SuccessAnticipations] {
+
+	if empty, left := checkEmpty[SuccessAnticipations]("recipiente id", recipientId); empty {
+		return left
+	}
+
+	if query == nil {
+		query = NewAnticipationQuery()
+	}
+
+	uri := fmt.Sprintf("/recipients/%v/anticipations?%v", recipientId, query.UrlQuery())
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Anticipations]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAnticipations {
+				return NewSuccessSlice[Anticipations](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeRecipient) GetAnticipation(recipientId string, anticipationId string) *either.Either[*ErrorResponse, SuccessAnticipation] {
+
+	if empty, left := checkEmpty[SuccessAnticipation]("recipiente id and anticipation id", recipientId, anticipationId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/recipients/%v/anticipations/%v", recipientId, anticipationId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Anticipation]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessAnticipation {
+				return NewSuccess[AnticipationPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeRecipient) onValidAnticipation(amount int64, timeframe AnticipationTimeframe, paymentDate time.Time) bool {
+	this.resetValidation()
+	valid := true
+
+	if amount <= 0 {
+		this.SetValidationError("Amount", "Amount must be bigger than zero")
+		valid = false
+	}
+
+	if timeframe != TimeframeStart && timeframe != TimeframeEnd {
+		this.SetValidationError("Timeframe", "Timeframe must be start or end")
+		valid = false
+	}
+
+	if paymentDate.IsZero() {
+		this.SetValidationError("PaymentDate", "PaymentDate is required")
+		valid = false
+	}
+
+	return valid
+}
+
 func (this *PagarmeRecipient) validate(recipient *Recipient) bool {
 	this.EntityValidator.AddEntity(recipient)
 	this.EntityValidator.AddEntity(recipient.TransferSettings)
@@ -259,3 +485,73 @@ func transferSettingsValidator(entity interface{}, validator *validator.Validati
 		}
 	}
 }
+
+func (this *PagarmeRecipient) validateRegister(recipient *RecipientRegister) bool {
+	this.EntityValidator.AddEntity(recipient)
+	this.EntityValidator.AddValidationForType(reflect.TypeOf(recipient), recipientRegisterValidator)
+	if recipient.DefaultBankAccount != nil {
+		this.EntityValidator.AddEntity(recipient.DefaultBankAccount)
+	}
+	if recipient.TransferSettings != nil {
+		this.EntityValidator.AddEntity(recipient.TransferSettings)
+		this.EntityValidator.AddValidationForType(reflect.TypeOf(recipient.TransferSettings), transferSettingsValidator)
+	}
+	return this.processValidator()
+}
+
+func recipientRegisterValidator(entity interface{}, validator *validator.Validation) {
+	recipient := entity.(*RecipientRegister)
+
+	if recipient.DefaultBankAccount == nil {
+		validator.SetError("DefaultBankAccount", "DefaultBankAccount is required")
+	}
+
+	info := recipient.RegisterInformation
+
+	if info == nil {
+		validator.SetError("RegisterInformation", "RegisterInformation is required")
+		return
+	}
+
+	if len(info.Email) == 0 || len(info.Document) == 0 {
+		validator.SetError("RegisterInformation", "Email and Document is required")
+	}
+
+	if len(info.PhoneNumbers) == 0 {
+		validator.SetError("PhoneNumbers", "PhoneNumbers is required")
+	}
+
+	switch info.Type {
+	case RegisterIndividual:
+		if len(info.Name) == 0 || len(info.Birthdate) == 0 || len(info.ProfessionalOccupation) == 0 {
+			validator.SetError("RegisterInformation", "Name, Birthdate and ProfessionalOccupation is required")
+		}
+		if info.MonthlyIncome <= 0 {
+			validator.SetError("MonthlyIncome", "MonthlyIncome must be bigger than zero")
+		}
+		if info.Address == nil {
+			validator.SetError("Address", "Address is required")
+		}
+	case RegisterCorporation:
+		if len(info.CompanyName) == 0 || len(info.TradingName) == 0 {
+			validator.SetError("RegisterInformation", "CompanyName and TradingName is required")
+		}
+		if info.AnnualRevenue <= 0 {
+			validator.SetError("AnnualRevenue", "AnnualRevenue must be bigger than zero")
+		}
+		if info.MainAddress == nil {
+			validator.SetError("MainAddress", "MainAddress is required")
+		}
+		if len(info.ManagingPartners) == 0 {
+			validator.SetError("ManagingPartners", "ManagingPartners is required")
+		}
+		for _, it := range info.ManagingPartners {
+			if len(it.Name) == 0 || len(it.Document) == 0 || it.Address == nil {
+				validator.SetError("ManagingPartners", "ManagingPartner Name, Document and Address is required")
+				break
+			}
+		}
+	default:
+		validator.SetError("Type", "Type must be individual or corporation")
+	}
+}
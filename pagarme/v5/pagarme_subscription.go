@@ -6,6 +6,7 @@ import (
 	"github.com/mobilemindtec/go-utils/v2/either"
 	"github.com/mobilemindtec/go-utils/v2/maps"
 	"reflect"
+	"time"
 )
 
 type SuccessSubscription = *Success[SubscriptionPtr]
@@ -14,6 +15,18 @@ type SuccessSubscriptions = *Success[Subscriptions]
 type SuccessSubscriptionItem = *Success[SubscriptionItemPtr]
 type SuccessSubscriptionItems = *Success[SubscriptionItems]
 
+type SuccessDiscount = *Success[DiscountPtr]
+type SuccessDiscounts = *Success[Discounts]
+
+type SuccessIncrement = *Success[IncrementPtr]
+type SuccessIncrements = *Success[Increments]
+
+type SuccessUsage = *Success[UsagePtr]
+type SuccessUsages = *Success[Usages]
+
+type SuccessCycle = *Success[CyclePtr]
+type SuccessCycles = *Success[Cycles]
+
 type CancelPendingInvoices bool
 type CardId string
 
@@ -191,6 +204,460 @@ func (this *PagarmeSubscription) UpdateItem(subscriptionId string, itemId string
 			})
 }
 
+func (this *PagarmeSubscription) AddDiscount(subscriptionId string, discount DiscountPtr) *either.Either[*ErrorResponse, SuccessDiscount] {
+
+	if empty, left := checkEmpty[SuccessDiscount]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	if discount == nil {
+		this.resetValidation()
+		this.SetValidationError("Discount", "Discount is required")
+		return either.Left[*ErrorResponse, SuccessDiscount](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	if !this.onValidOperation(discount.Value, discount.DiscountType, discount.Cycles, "Discount") {
+		return either.Left[*ErrorResponse, SuccessDiscount](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/discounts", subscriptionId)
+
+	return either.
+		MapIf(
+			this.post(uri, discount, createParser[Discount]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessDiscount {
+				return NewSuccess[DiscountPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeSubscription) AddItemDiscount(subscriptionId string, itemId string, discount DiscountPtr) *either.Either[*ErrorResponse, SuccessDiscount] {
+
+	if empty, left := checkEmpty[SuccessDiscount]("subscription item id", itemId); empty {
+		return left
+	}
+
+	// nil is validated by AddDiscount
+	if discount != nil {
+		discount.ItemId = itemId
+	}
+
+	return this.AddDiscount(subscriptionId, discount)
+}
+
+func (this *PagarmeSubscription) GetDiscount(subscriptionId string, discountId string) *either.Either[*ErrorResponse, SuccessDiscount] {
+
+	if empty, left := checkEmpty[SuccessDiscount]("subscription id and discount id", subscriptionId, discountId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/discounts/%v", subscriptionId, discountId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Discount]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessDiscount {
+				return NewSuccess[DiscountPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeSubscription) ListDiscounts(subscriptionId string) *either.Either[*ErrorResponse, SuccessDiscounts] {
+
+	if empty, left := checkEmpty[SuccessDiscounts]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/discounts", subscriptionId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Discounts]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessDiscounts {
+				return NewSuccessSlice[Discounts](e.UnwrapRight())
+			})
+}
+
+// ListItemDiscounts descontos aplicados somente ao item da assinatura
+func (this *PagarmeSubscription) ListItemDiscounts(subscriptionId string, itemId string) *either.Either[*ErrorResponse, SuccessDiscounts] {
+
+	if empty, left := checkEmpty[SuccessDiscounts]("subscription item id", itemId); empty {
+		return left
+	}
+
+	result := this.ListDiscounts(subscriptionId)
+
+	if result.IsRight() {
+		success := result.UnwrapRight()
+		discounts := Discounts{}
+		for _, it := range success.Data {
+			if it.GetItemId() == itemId {
+				discounts = append(discounts, it)
+			}
+		}
+		success.Data = discounts
+	}
+
+	return result
+}
+
+func (this *PagarmeSubscription) DeleteDiscount(subscriptionId string, discountId string) *either.Either[*ErrorResponse, SuccessBool] {
+
+	if empty, left := checkEmpty[SuccessBool]("subscription id and discount id", subscriptionId, discountId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/discounts/%v", subscriptionId, discountId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessBool {
+				return NewSuccessWithValue[bool](e.UnwrapRight(), true)
+			})
+}
+
+func (this *PagarmeSubscription) AddIncrement(subscriptionId string, increment IncrementPtr) *either.Either[*ErrorResponse, SuccessIncrement] {
+
+	if empty, left := checkEmpty[SuccessIncrement]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	if increment == nil {
+		this.resetValidation()
+		this.SetValidationError("Increment", "Increment is required")
+		return either.Left[*ErrorResponse, SuccessIncrement](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	if !this.onValidOperation(increment.Value, increment.IncrementType, increment.Cycles, "Increment") {
+		return either.Left[*ErrorResponse, SuccessIncrement](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/increments", subscriptionId)
+
+	return either.
+		MapIf(
+			this.post(uri, increment, createParser[Increment]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessIncrement {
+				return NewSuccess[IncrementPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeSubscription) AddItemIncrement(subscriptionId string, itemId string, increment IncrementPtr) *either.Either[*ErrorResponse, SuccessIncrement] {
+
+	if empty, left := checkEmpty[SuccessIncrement]("subscription item id", itemId); empty {
+		return left
+	}
+
+	// nil is validated by AddIncrement
+	if increment != nil {
+		increment.ItemId = itemId
+	}
+
+	return this.AddIncrement(subscriptionId, increment)
+}
+
+func (this *PagarmeSubscription) GetIncrement(subscriptionId string, incrementId string) *either.Either[*ErrorResponse, SuccessIncrement] {
+
+	if empty, left := checkEmpty[SuccessIncrement]("subscription id and increment id", subscriptionId, incrementId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/increments/%v", subscriptionId, incrementId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Increment]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessIncrement {
+				return NewSuccess[IncrementPtr](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeSubscription) ListIncrements(subscriptionId string) *either.Either[*ErrorResponse, SuccessIncrements] {
+
+	if empty, left := checkEmpty[SuccessIncrements]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/increments", subscriptionId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Increments]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessIncrements {
+				return NewSuccessSlice[Increments](e.UnwrapRight())
+			})
+}
+
+// ListItemIncrements incrementos aplicados somente ao item da assinatura
+func (this *PagarmeSubscription) ListItemIncrements(subscriptionId string, itemId string) *either.Either[*ErrorResponse, SuccessIncrements] {
+
+	if empty, left := checkEmpty[SuccessIncrements]("subscription item id", itemId); empty {
+		return left
+	}
+
+	result := this.ListIncrements(subscriptionId)
+
+	if result.IsRight() {
+		success := result.UnwrapRight()
+		increments := Increments{}
+		for _, it := range success.Data {
+			if it.GetItemId() == itemId {
+				increments = append(increments, it)
+			}
+		}
+		success.Data = increments
+	}
+
+	return result
+}
+
+func (this *PagarmeSubscription) DeleteIncrement(subscriptionId string, incrementId string) *either.Either[*ErrorResponse, SuccessBool] {
+
+	if empty, left := checkEmpty[SuccessBool]("subscription id and increment id", subscriptionId, incrementId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/increments/%v", subscriptionId, incrementId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessBool {
+				return NewSuccessWithValue[bool](e.UnwrapRight(), true)
+			})
+}
+
+func (this *PagarmeSubscription) ReportUsage(subscriptionId string, itemId string, usage UsagePtr) *either.Either[*ErrorResponse, SuccessUsage] {
+
+	if empty, left := checkEmpty[SuccessUsage]("subscription id and subscription item id", subscriptionId, itemId); empty {
+		return left
+	}
+
+	this.resetValidation()
+
+	if usage == nil {
+		this.SetValidationError("Usage", "Usage is required")
+		return either.Left[*ErrorResponse, SuccessUsage](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	valid := true
+
+	if usage.Quantity <= 0 {
+		this.SetValidationError("Quantity", "Quantity must be bigger than zero")
+		valid = false
+	}
+
+	if len(usage.UsedAt) == 0 {
+		this.SetValidationError("UsedAt", "UsedAt is required")
+		valid = false
+	}
+
+	if !valid {
+		return either.Left[*ErrorResponse, SuccessUsage](
+			NewErrorResponseWithErrors(this.getMessage("Pagarme.ValidationError"), this.validationsToMapOfStringSlice()))
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/items/%v/usages", subscriptionId, itemId)
+
+	return either.
+		MapIf(
+			this.post(uri, usage, createParser[Usage]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessUsage {
+				return NewSuccess[UsagePtr](e.UnwrapRight())
+			})
+}
+
+// ListUsages usos do item, filtre pelo ciclo com UsageQuery.WithCycle
+func (this *PagarmeSubscription) ListUsages(subscriptionId string, itemId string, query *UsageQuery) *either.Either[*ErrorResponse, SuccessUsages] {
+
+	if empty, left := checkEmpty[SuccessUsages]("subscription id and subscription item id", subscriptionId, itemId); empty {
+		return left
+	}
+
+	if query == nil {
+		query = NewUsageQuery()
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/items/%v/usages?%v", subscriptionId, itemId, query.UrlQuery())
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Usages]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessUsages {
+				return NewSuccessSlice[Usages](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeSubscription) DeleteUsage(subscriptionId string, itemId string, usageId string) *either.Either[*ErrorResponse, SuccessBool] {
+
+	if empty, left := checkEmpty[SuccessBool]("subscription id, subscription item id and usage id", subscriptionId, itemId, usageId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/items/%v/usages/%v", subscriptionId, itemId, usageId)
+
+	return either.
+		MapIf(
+			this.delete(uri, nil),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessBool {
+				return NewSuccessWithValue[bool](e.UnwrapRight(), true)
+			})
+}
+
+func (this *PagarmeSubscription) ListCycles(subscriptionId string, query *CycleQuery) *either.Either[*ErrorResponse, SuccessCycles] {
+
+	if empty, left := checkEmpty[SuccessCycles]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	if query == nil {
+		query = NewCycleQuery()
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/cycles?%v", subscriptionId, query.UrlQuery())
+
+	return either.
+		MapIf(
+			this.get(uri, createParserContent[Cycles]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessCycles {
+				return NewSuccessSlice[Cycles](e.UnwrapRight())
+			})
+}
+
+func (this *PagarmeSubscription) GetCycle(subscriptionId string, cycleId string) *either.Either[*ErrorResponse, SuccessCycle] {
+
+	if empty, left := checkEmpty[SuccessCycle]("subscription id and cycle id", subscriptionId, cycleId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/cycles/%v", subscriptionId, cycleId)
+
+	return either.
+		MapIf(
+			this.get(uri, createParser[Cycle]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessCycle {
+				return NewSuccess[CyclePtr](e.UnwrapRight())
+			})
+}
+
+// RenewCycle antecipa a renovação, fecha o ciclo atual e cria o próximo. A API v5 não
+// possui endpoint para somente fechar o ciclo, o fechamento ocorre na renovação
+func (this *PagarmeSubscription) RenewCycle(subscriptionId string) *either.Either[*ErrorResponse, SuccessCycle] {
+
+	if empty, left := checkEmpty[SuccessCycle]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/cycles", subscriptionId)
+
+	return either.
+		MapIf(
+			this.post(uri, nil, createParser[Cycle]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessCycle {
+				return NewSuccess[CyclePtr](e.UnwrapRight())
+			})
+}
+
+// UpdateBillingDate altera a data da próxima cobrança
+func (this *PagarmeSubscription) UpdateBillingDate(subscriptionId string, nextBillingAt time.Time) *either.Either[*ErrorResponse, SuccessSubscription] {
+
+	if nextBillingAt.IsZero() {
+		return either.Left[*ErrorResponse, SuccessSubscription](
+			NewErrorResponse("next billing date is required"))
+	}
+
+	return this.updateField(subscriptionId, "billing-date", maps.JSON("next_billing_at", nextBillingAt.Format(DateLayout)))
+}
+
+func (this *PagarmeSubscription) UpdateStartAt(subscriptionId string, startAt time.Time) *either.Either[*ErrorResponse, SuccessSubscription] {
+
+	if startAt.IsZero() {
+		return either.Left[*ErrorResponse, SuccessSubscription](
+			NewErrorResponse("start date is required"))
+	}
+
+	return this.updateField(subscriptionId, "start-at", maps.JSON("start_at", startAt.Format(DateLayout)))
+}
+
+func (this *PagarmeSubscription) UpdateMinimumPrice(subscriptionId string, minimumPrice int64) *either.Either[*ErrorResponse, SuccessSubscription] {
+
+	if minimumPrice < 0 {
+		return either.Left[*ErrorResponse, SuccessSubscription](
+			NewErrorResponse("minimum price can't be negative"))
+	}
+
+	return this.updateField(subscriptionId, "minimum_price", maps.JSON("minimum_price", minimumPrice))
+}
+
+func (this *PagarmeSubscription) UpdateMetadata(subscriptionId string, metadata map[string]string) *either.Either[*ErrorResponse, SuccessSubscription] {
+	return this.updateField(subscriptionId, "metadata", maps.JSON("metadata", metadata))
+}
+
+func (this *PagarmeSubscription) updateField(subscriptionId string, field string, payload interface{}) *either.Either[*ErrorResponse, SuccessSubscription] {
+
+	if empty, left := checkEmpty[SuccessSubscription]("subscription id", subscriptionId); empty {
+		return left
+	}
+
+	uri := fmt.Sprintf("/subscriptions/%v/%v", subscriptionId, field)
+
+	return either.
+		MapIf(
+			this.patch(uri, payload, createParser[Subscription]()),
+			func(e *either.Either[error, *Response]) *ErrorResponse {
+				return unwrapError(e.UnwrapLeft())
+			},
+			func(e *either.Either[error, *Response]) SuccessSubscription {
+				return NewSuccess[SubscriptionPtr](e.UnwrapRight())
+			})
+}
+
 func (this *PagarmeSubscription) validate(subscription *Subscription) bool {
 	this.EntityValidator.AddEntity(subscription)
 	this.EntityValidator.AddValidationForType(reflect.TypeOf(subscription), this.subscriptionValidator)
@@ -213,15 +680,51 @@ func (this *PagarmeSubscription) subscriptionValidator(entity interface{}, valid
 	}
 
 	for _, it := range s.Items {
-		if it.PricingScheme == nil || it.PricingScheme.Price <= 0 {
-			validator.SetError("PricingScheme", "Item PricingScheme must be bigger than zero")
-		}
+		validatePricingScheme(it.PricingScheme, func(key string, message string) {
+			validator.SetError(key, message)
+		})
 	}
 
 	if s.Customer == nil && len(s.CustomerId) == 0 {
 		validator.SetError("Customer", "Customer or CustomerId is required")
 	}
 
+	for _, it := range s.Discounts {
+		if it.Value <= 0 || (it.DiscountType != Flat && it.DiscountType != Percentage) {
+			validator.SetError("Discounts", "Discount value and type flat or percentage is required")
+		}
+	}
+
+	for _, it := range s.Increments {
+		if it.Value <= 0 || (it.IncrementType != Flat && it.IncrementType != Percentage) {
+			validator.SetError("Increments", "Increment value and type flat or percentage is required")
+		}
+	}
+
+	if s.Split != nil && s.Split.Enabled {
+		if len(s.Split.Rules) == 0 {
+			validator.SetError("Split", "Split rules is required")
+		}
+
+		// com descontos, incrementos ou cobrança por uso o valor do ciclo varia, então só aceita percentual
+		variable := len(s.Discounts) > 0 || len(s.Increments) > 0
+		for _, it := range s.Items {
+			if it.PricingScheme != nil && it.PricingScheme.IsTiered() {
+				variable = true
+			}
+		}
+		for _, it := range s.Split.Rules {
+			if variable && it != nil && it.Type == Flat {
+				validator.SetError("Split", "Flat split requires a fixed cycle amount, use percentage")
+				break
+			}
+		}
+
+		validateSplits(s.GetCycleAmount(), s.Split.Rules, func(key string, message string) {
+			validator.SetError(key, message)
+		})
+	}
+
 	if len(s.CardId) == 0 && len(s.CardToken) == 0 {
 
 		if s.Card == nil {
@@ -243,3 +746,77 @@ func (this *PagarmeSubscription) subscriptionValidator(entity interface{}, valid
 		
 	}
 }
+
+func (this *PagarmeSubscription) onValidOperation(value int64, operationType OperationType, cycles int64, name string) bool {
+	this.resetValidation()
+	valid := true
+
+	if value <= 0 {
+		this.SetValidationError(name, fmt.Sprintf("%v value must be bigger than zero", name))
+		valid = false
+	}
+
+	switch operationType {
+	case Flat:
+	case Percentage:
+		if value > 100 {
+			this.SetValidationError(name, fmt.Sprintf("%v percentage must be between 1 and 100", name))
+			valid = false
+		}
+	default:
+		this.SetValidationError(name, fmt.Sprintf("%v type must be flat or percentage", name))
+		valid = false
+	}
+
+	if cycles < 0 {
+		this.SetValidationError("Cycles", "Cycles must be zero or bigger")
+		valid = false
+	}
+
+	return valid
+}
+
+// validatePricingScheme unit exige price, package, volume e tier exigem faixas
+// contínuas começando em 1 onde só a última pode não ter limite
+func validatePricingScheme(scheme *PricingScheme, setError func(key string, message string)) {
+
+	if scheme == nil {
+		setError("PricingScheme", "Item PricingScheme must be bigger than zero")
+		return
+	}
+
+	if !scheme.IsTiered() {
+		if scheme.Price <= 0 {
+			setError("PricingScheme", "Item PricingScheme must be bigger than zero")
+		}
+		return
+	}
+
+	if len(scheme.PriceBrackets) == 0 {
+		setError("PriceBrackets", "PriceBrackets is required to scheme type package, volume or tier")
+		return
+	}
+
+	var next int64 = 1
+	last := len(scheme.PriceBrackets) - 1
+
+	for i, it := range scheme.PriceBrackets {
+		if it.StartQuantity != next {
+			setError("PriceBrackets", fmt.Sprintf("PriceBracket %v must start at quantity %v", i, next))
+			return
+		}
+		if it.EndQuantity == 0 && i != last {
+			setError("PriceBrackets", "Only the last PriceBracket can have no end quantity")
+			return
+		}
+		if it.EndQuantity != 0 && it.EndQuantity < it.StartQuantity {
+			setError("PriceBrackets", fmt.Sprintf("PriceBracket %v end quantity must be bigger than start quantity", i))
+			return
+		}
+		if it.Price < 0 || it.OveragePrice < 0 {
+			setError("PriceBrackets", fmt.Sprintf("PriceBracket %v price can't be negative", i))
+			return
+		}
+		next = it.EndQuantity + 1
+	}
+}
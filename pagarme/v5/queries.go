@@ -167,3 +167,123 @@ func (this *BalanceQuery) UrlQuery() string {
 func NewBalanceQuery() *BalanceQuery {
 	return &BalanceQuery{}
 }
+
+type UsageQuery struct {
+	CycleId   string    `jsonp:"cycle_id,omitempty"`
+	Code      string    `jsonp:"code,omitempty"`
+	Group     string    `jsonp:"group,omitempty"`
+	UsedSince time.Time `jsonp:"used_since,date,omitempty"`
+	UsedUntil time.Time `jsonp:"used_until,date,omitempty"`
+	Page      int       `jsonp:"page,omitempty"`
+	Size      int       `jsonp:"size,omitempty"`
+}
+
+func NewUsageQuery() *UsageQuery {
+	return &UsageQuery{}
+}
+
+func (this *UsageQuery) WithCycle(cycleId string) *UsageQuery {
+	this.CycleId = cycleId
+	return this
+}
+
+func (this *UsageQuery) UrlQuery() string {
+	m, _ := json.EncodeAsMap(this)
+	return maps.ToUrlQuery(m)
+}
+
+type CycleQuery struct {
+	Status string `jsonp:"status,omitempty"`
+	Page   int    `jsonp:"page,omitempty"`
+	Size   int    `jsonp:"size,omitempty"`
+}
+
+func NewCycleQuery() *CycleQuery {
+	return &CycleQuery{}
+}
+
+func (this *CycleQuery) UrlQuery() string {
+	m, _ := json.EncodeAsMap(this)
+	return maps.ToUrlQuery(m)
+}
+
+type AnticipationQuery struct {
+	Status           AnticipationStatus    `jsonp:"status,omitempty"`
+	Timeframe        AnticipationTimeframe `jsonp:"timeframe,omitempty"`
+	PaymentDateSince time.Time             `jsonp:"payment_date_since,date,omitempty"`
+	PaymentDateUntil time.Time             `jsonp:"payment_date_until,date,omitempty"`
+	CreatedSince     time.Time             `jsonp:"created_since,date,omitempty"`
+	CreatedUntil     time.Time             `jsonp:"created_until,date,omitempty"`
+	Page             int                   `jsonp:"page,omitempty"`
+	Size             int                   `jsonp:"size,omitempty"`
+}
+
+func (this *AnticipationQuery) UrlQuery() string {
+	m, _ := json.EncodeAsMap(this)
+	return maps.ToUrlQuery(m)
+}
+
+func NewAnticipationQuery() *AnticipationQuery {
+	return &AnticipationQuery{}
+}
+
+type PayableQuery struct {
+	RecipientId      string        `jsonp:"recipient_id,omitempty"`
+	Status           PayableStatus `jsonp:"status,omitempty"`
+	ChargeId         string        `jsonp:"charge_id,omitempty"`
+	Installment      int           `jsonp:"installment,omitempty"`
+	Type             string        `jsonp:"type,omitempty"`
+	PaymentDateSince time.Time     `jsonp:"payment_date_since,date,omitempty"`
+	PaymentDateUntil time.Time     `jsonp:"payment_date_until,date,omitempty"`
+	Page             int           `jsonp:"page,omitempty"`
+	Size             int           `jsonp:"size,omitempty"`
+}
+
+func (this *PayableQuery) UrlQuery() string {
+	m, _ := json.EncodeAsMap(this)
+	return maps.ToUrlQuery(m)
+}
+
+func NewPayableQuery() *PayableQuery {
+	return &PayableQuery{}
+}
+
+func (this *PayableQuery) WithRecipient(recipientId string) *PayableQuery {
+	this.RecipientId = recipientId
+	return this
+}
+
+func (this *PayableQuery) WithPaymentDate(since time.Time, until time.Time) *PayableQuery {
+	this.PaymentDateSince = since
+	this.PaymentDateUntil = until
+	return this
+}
+
+type HookQuery struct {
+	Status       WebhookStatus `jsonp:"status,omitempty"`
+	Event        WebhookEvent  `jsonp:"event,omitempty"`
+	CreatedSince time.Time     `jsonp:"created_since,date,omitempty"`
+	CreatedUntil time.Time     `jsonp:"created_until,date,omitempty"`
+	Page         int           `jsonp:"page,omitempty"`
+	Size         int           `jsonp:"size,omitempty"`
+}
+
+func (this *HookQuery) UrlQuery() string {
+	m, _ := json.EncodeAsMap(this)
+	return maps.ToUrlQuery(m)
+}
+
+func NewHookQuery() *HookQuery {
+	return &HookQuery{}
+}
+
+func (this *HookQuery) WithStatus(status WebhookStatus) *HookQuery {
+	this.Status = status
+	return this
+}
+
+func (this *HookQuery) WithCreated(since time.Time, until time.Time) *HookQuery {
+	this.CreatedSince = since
+	this.CreatedUntil = until
+	return this
+}
@@ -1,26 +1,49 @@
 package v5
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/beego/validator"
 	"github.com/mobilemindtec/go-utils/support"
 	"strings"
 )
 
+const (
+	AuthorizationHeader = "Authorization"
+)
+
 type WebhookData struct {
+	// hook id, the same on provider retries
+	HookId     string       `json:"hook_id"`
 	Id         string       `json:"id" valid:"Required"`
 	Event      WebhookEvent `json:"event" valid:"Required"`
 	Status     string       `json:"status" valid:""`
 	Raw        string       `json:"raw" valid:"Required"`
 	PayloadMap map[string]interface{}
+
+	// typed webhook, *WebhookObject[*Order], *WebhookObject[*Charge], etc.
+	// nil when event has no typed model
+	Object interface{}
+	// status normalizado a partir do evento e do objeto
+	PaymentStatus api.PaymentStatus
 }
 
 func NewWebhookData() *WebhookData {
 	return &WebhookData{}
 }
 
+// DedupKey identify the event on retries. Uses the hook id and fallback to
+// event, object id and status
+func (this *WebhookData) DedupKey() string {
+	if len(this.HookId) > 0 {
+		return fmt.Sprintf("pagarme:%v", this.HookId)
+	}
+	return fmt.Sprintf("pagarme:%v:%v:%v", this.Event, this.Id, this.Status)
+}
+
 func (this *WebhookData) IsOrder() bool {
 	return strings.HasPrefix(string(this.Event), "order.")
 }
@@ -37,10 +60,157 @@ func (this *WebhookData) IsCharge() bool {
 	return strings.HasPrefix(string(this.Event), "charge.")
 }
 
+func (this *WebhookData) IsInvoice() bool {
+	return strings.HasPrefix(string(this.Event), "invoice.")
+}
+
+func (this *WebhookData) IsRecipient() bool {
+	return strings.HasPrefix(string(this.Event), "recipient.")
+}
+
+func (this *WebhookData) IsTransfer() bool {
+	return strings.HasPrefix(string(this.Event), "transfer.")
+}
+
+func (this *WebhookData) IsCheckout() bool {
+	return strings.HasPrefix(string(this.Event), "checkout.")
+}
+
+func (this *WebhookData) Order() (*WebhookObject[*Order], bool) {
+	return WebhookObjectAs[*Order](this)
+}
+
+func (this *WebhookData) Charge() (*WebhookObject[*Charge], bool) {
+	return WebhookObjectAs[*Charge](this)
+}
+
+func (this *WebhookData) Subscription() (*WebhookObject[*Subscription], bool) {
+	return WebhookObjectAs[*Subscription](this)
+}
+
+func (this *WebhookData) Invoice() (*WebhookObject[*Invoice], bool) {
+	return WebhookObjectAs[*Invoice](this)
+}
+
+func (this *WebhookData) Recipient() (*WebhookObject[*Recipient], bool) {
+	return WebhookObjectAs[*Recipient](this)
+}
+
+func (this *WebhookData) Transfer() (*WebhookObject[*Transfer], bool) {
+	return WebhookObjectAs[*Transfer](this)
+}
+
+func (this *WebhookData) Checkout() (*WebhookObject[*Checkout], bool) {
+	return WebhookObjectAs[*Checkout](this)
+}
+
+// RecipientStatus status e pendências do recebedor, enviado em recipient.updated
+func (this *WebhookData) RecipientStatus() (RecipientStatus, []RecipientRequirement, bool) {
+	if obj, ok := this.Recipient(); ok && obj.Data != nil {
+		return obj.Data.Status, obj.Data.PendingRequirements(), true
+	}
+	return "", nil, false
+}
+
+// WebhookObjectAs get the typed webhook object decoded by Parse
+func WebhookObjectAs[T any](data *WebhookData) (*WebhookObject[T], bool) {
+	obj, ok := data.Object.(*WebhookObject[T])
+	return obj, ok && obj != nil
+}
+
+func decodeWebhookObject[T any](body []byte) (*WebhookObject[T], error) {
+	obj := new(WebhookObject[T])
+	if err := json.Unmarshal(body, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// decodeObject decode the webhook payload based on event prefix
+func (this *WebhookData) decodeObject(body []byte) error {
+
+	var err error
+
+	switch {
+	case this.IsOrder():
+		this.Object, err = decodeWebhookObject[*Order](body)
+	case this.IsCharge():
+		this.Object, err = decodeWebhookObject[*Charge](body)
+	case this.IsSubscription():
+		this.Object, err = decodeWebhookObject[*Subscription](body)
+	case this.IsInvoice():
+		this.Object, err = decodeWebhookObject[*Invoice](body)
+	case this.IsRecipient():
+		this.Object, err = decodeWebhookObject[*Recipient](body)
+	case this.IsTransfer():
+		this.Object, err = decodeWebhookObject[*Transfer](body)
+	case this.IsCheckout():
+		this.Object, err = decodeWebhookObject[*Checkout](body)
+	}
+
+	return err
+}
+
+func (this *WebhookData) resolvePaymentStatus() api.PaymentStatus {
+
+	switch this.Event {
+	case EventOrderPaid, EventChargePaid, EventInvoicePaid:
+		return api.PaymentPaid
+	case EventOrderPaymentFailed, EventChargePaymentFailed, EventInvoicePaymentFailed,
+		EventChargeAntifraudReproved:
+		return api.PaymentRefused
+	case EventOrderCanceled, EventInvoiceCanceled, EventCheckoutCanceled:
+		return api.PaymentCancelled
+	case EventChargeRefunded:
+		return api.PaymentRefound
+	case EventChargeChargedback:
+		return api.PaymentChargeback
+	}
+
+	if obj, ok := this.Order(); ok && obj.Data != nil {
+		return obj.Data.ToPaymentStatus()
+	}
+
+	if obj, ok := this.Charge(); ok && obj.Data != nil {
+		if obj.Data.Status == ChargeCanceled {
+			return api.PaymentCancelled
+		}
+		return obj.Data.ToPaymentStatus()
+	}
+
+	if obj, ok := this.Invoice(); ok && obj.Data != nil {
+		return obj.Data.ToPaymentStatus()
+	}
+
+	if obj, ok := this.Checkout(); ok && obj.Data != nil {
+		switch obj.Data.Status {
+		case CheckoutOpen:
+			return api.PaymentWaitingPayment
+		case CheckoutExpired:
+			return api.PaymentCancelled
+		}
+	}
+
+	if obj, ok := this.Subscription(); ok && obj.Data != nil {
+		switch obj.Data.Status {
+		case Canceled:
+			return api.PaymentCancelled
+		case Failed:
+			return api.PaymentRefused
+		}
+	}
+
+	return api.PaymentOther
+}
+
 type Webhook struct {
 	JsonParser *support.JsonParser
 	Debug      bool
 
+	// credenciais Basic auth configuradas no webhook da dashboard
+	Username string
+	Password string
+
 	EntityValidator    *validator.EntityValidator
 	ValidationErrors   map[string]string
 	HasValidationError bool
@@ -66,6 +236,48 @@ func (this *Webhook) SetDebug() {
 	this.Debug = true
 }
 
+func (this *Webhook) SetBasicAuth(username string, password string) *Webhook {
+	this.Username = username
+	this.Password = password
+	return this
+}
+
+// Verify check the Authorization header against the configured Basic auth credentials
+func (this *Webhook) Verify(authorization string) error {
+
+	const prefix = "Basic "
+
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return api.NewWebhookAuthError(api.GatewayPagarme, "basic authorization is required")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(authorization[len(prefix):]))
+
+	if err != nil {
+		return api.NewWebhookAuthError(api.GatewayPagarme, "invalid basic authorization")
+	}
+
+	username, password, _ := strings.Cut(string(decoded), ":")
+
+	// compare both to not leak which one is wrong
+	validUsername := api.SecureCompare(this.Username, username)
+	validPassword := api.SecureCompare(this.Password, password)
+
+	if !validUsername || !validPassword {
+		return api.NewWebhookAuthError(api.GatewayPagarme, "invalid credentials")
+	}
+
+	return nil
+}
+
+// ParseAndVerify verify the request and parse the body only when authenticated
+func (this *Webhook) ParseAndVerify(authorization string, body []byte) (*WebhookData, error) {
+	if err := this.Verify(authorization); err != nil {
+		return nil, err
+	}
+	return this.Parse(body)
+}
+
 func ParseWebhookObject(body []byte, entity interface{}) error {
 	return json.Unmarshal(body, entity)
 }
@@ -87,6 +299,7 @@ func (this *Webhook) Parse(body []byte) (*WebhookData, error) {
 	}
 
 	payload := this.JsonParser.GetJsonObject(jsonMap, "data")
+	data.HookId = this.JsonParser.GetJsonString(jsonMap, "id")
 	data.Id = this.JsonParser.GetJsonString(payload, "id")
 	data.Status = this.JsonParser.GetJsonString(payload, "status")
 	data.PayloadMap = jsonMap
@@ -102,6 +315,12 @@ func (this *Webhook) Parse(body []byte) (*WebhookData, error) {
 		return nil, errors.New("validation error")
 	}
 
+	if err := data.decodeObject(body); err != nil {
+		return nil, fmt.Errorf("error on decode webhook %v: %v", data.Event, err)
+	}
+
+	data.PaymentStatus = data.resolvePaymentStatus()
+
 	return data, nil
 
 }
@@ -0,0 +1,54 @@
+package v5
+
+import (
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	"net/http"
+)
+
+type WebhookCallback func(r *http.Request, data *WebhookData) error
+
+// WebhookHandler is a http.Handler to Pagarme webhooks. On beego, register
+// with web.Handler(path, handler) or call ServeHTTP(ctx.ResponseWriter, ctx.Request).
+//
+// Pagarme retries any non 2xx response. Invalid Basic auth credentials are
+// replied with 401, invalid body with 400 and callback errors with 500.
+type WebhookHandler struct {
+	Webhook     *Webhook
+	Callback    WebhookCallback
+	MaxBodySize int64
+}
+
+func NewWebhookHandler(webhook *Webhook, callback WebhookCallback) *WebhookHandler {
+	return &WebhookHandler{Webhook: webhook, Callback: callback, MaxBodySize: api.DefaultWebhookMaxBodySize}
+}
+
+func (this *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, err := api.ReadWebhookBody(w, r, this.MaxBodySize)
+
+	if err != nil {
+		api.WriteWebhookError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// copy per request, validation state is kept on webhook
+	webhook := *this.Webhook
+
+	data, err := webhook.ParseAndVerify(r.Header.Get(AuthorizationHeader), body)
+
+	if err != nil {
+		if webhook.Debug {
+			fmt.Println("**** Pagarmev5.WebhookHandler: ", err)
+		}
+		api.WriteWebhookError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := this.Callback(r, data); err != nil {
+		api.WriteWebhookError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	api.WriteWebhookAck(w)
+}
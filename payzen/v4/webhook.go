@@ -8,7 +8,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
-	_ "fmt"
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-utils/beego/validator"
 	"github.com/mobilemindtec/go-utils/support"
 	"net/url"
@@ -31,10 +32,34 @@ func NewWebhookData() *WebhookData {
 	return &WebhookData{Answer: new(Answer)}
 }
 
+// DedupKey identify the event on retries by transaction uuid and status
+func (this *WebhookData) DedupKey() string {
+	if len(this.Answer.Transactions) > 0 {
+		transaction := this.Answer.Transactions[0]
+		return fmt.Sprintf("payzen:%v:%v:%v", transaction.Uuid, transaction.Status, transaction.DetailedStatus)
+	}
+	orderId := this.Answer.OrderId
+	if this.Answer.OrderDetails != nil && len(orderId) == 0 {
+		orderId = this.Answer.OrderDetails.OrderId
+	}
+	return fmt.Sprintf("payzen:%v:%v", orderId, this.Answer.OrderStatus)
+}
+
+const (
+	// kr-hash-key values
+	HashKeyPassword   = "password"    // IPN, assinado com a senha da API
+	HashKeySha256Hmac = "sha256_hmac" // retorno do navegador, assinado com a chave HMAC-SHA-256
+)
+
 type Webhook struct {
 	JsonParser *support.JsonParser
 	Debug      bool
 
+	// senha da API, usada para assinar o IPN
+	Password string
+	// chave HMAC-SHA-256, usada para assinar o retorno do navegador
+	HmacKey string
+
 	EntityValidator    *validator.EntityValidator
 	ValidationErrors   map[string]string
 	HasValidationError bool
@@ -60,10 +85,62 @@ func (this *Webhook) SetDebug() {
 	this.Debug = true
 }
 
+func (this *Webhook) SetKeys(password string, hmacKey string) *Webhook {
+	this.Password = password
+	this.HmacKey = hmacKey
+	return this
+}
+
+// Verify check kr-hash against the signature of kr-answer
+func (this *Webhook) Verify(data *WebhookData) error {
+
+	if data.KrHashAlgorithm != "sha256_hmac" {
+		return api.NewWebhookAuthError(api.GatewayPayZen, "unsupported kr-hash-algorithm")
+	}
+
+	var key string
+
+	switch data.KrHashKey {
+	case HashKeyPassword:
+		key = this.Password
+	case HashKeySha256Hmac:
+		key = this.HmacKey
+	}
+
+	if len(key) == 0 {
+		return api.NewWebhookAuthError(api.GatewayPayZen, "no key to kr-hash-key "+data.KrHashKey)
+	}
+
+	if !api.SecureCompare(GenerateSignatureFromBody(key, data.KrAnswer), strings.ToLower(data.KrHash)) {
+		return api.NewWebhookAuthError(api.GatewayPayZen, "invalid kr-hash")
+	}
+
+	return nil
+}
+
+// ParseAndVerify parse the body and verify the signature
+func (this *Webhook) ParseAndVerify(body []byte) (*WebhookData, error) {
+
+	data, err := this.Parse(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := this.Verify(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
 func (this *Webhook) Parse(body []byte) (*WebhookData, error) {
 
 	formData := make(map[string]interface{})
-	urlQuery, err := url.QueryUnescape(string(body))
+
+	// kr-answer is a json and may contain = and &, so the body is parsed as form
+	// and the values are unescaped one by one
+	values, err := url.ParseQuery(string(body))
 
 	if err != nil {
 		return nil, err
@@ -71,35 +148,27 @@ func (this *Webhook) Parse(body []byte) (*WebhookData, error) {
 
 	data := NewWebhookData()
 
-	splited := strings.Split(urlQuery, "&")
-
-	for _, value := range splited {
+	for key := range values {
 
-		vals := strings.Split(value, "=")
+		value := values.Get(key)
 
-		switch vals[0] {
+		switch key {
 		case "kr-hash-key":
-			data.KrHashKey = vals[1]
-			break
+			data.KrHashKey = value
 		case "kr-answer-type":
-			data.KrAnswerType = vals[1]
-			break
+			data.KrAnswerType = value
 		case "kr-hash-algorithm":
-			data.KrHashAlgorithm = vals[1]
-			break
+			data.KrHashAlgorithm = value
 		case "kr-hash":
-			data.KrHash = vals[1]
-			break
+			data.KrHash = value
 		case "kr-answer":
-			if err := json.Unmarshal([]byte(vals[1]), data.Answer); err != nil {
+			if err := json.Unmarshal([]byte(value), data.Answer); err != nil {
 				return nil, err
 			}
-			data.KrAnswer = vals[1]
-			formData[vals[0]] = data.Answer
-			break
+			data.KrAnswer = value
+			formData[key] = data.Answer
 		default:
-			formData[vals[0]] = vals[1]
-			break
+			formData[key] = value
 		}
 
 	}
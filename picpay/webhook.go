@@ -7,6 +7,10 @@ import (
   "errors"
 )
 
+const (
+  SellerTokenHeader = "x-seller-token"
+)
+
 
 type WebhookData struct {
   ReferenceId string `json:"referenceId" valid:"Required"`
@@ -25,6 +29,9 @@ type Webhook struct {
   SallerToken string
   Debug bool
 
+  // used to check the real transaction status
+  PicPay *PicPay
+
   EntityValidator *validator.EntityValidator  
   ValidationErrors map[string]string
   HasValidationError bool
@@ -47,10 +54,88 @@ func NewDefaultWebhook() *Webhook{
   }
 }
 
+func NewWebhookWithPicPay(lang string, token string, sallerToken string) *Webhook {
+  webhook := NewWebhook(lang, sallerToken)
+  webhook.PicPay = NewPicPay(lang, token, sallerToken)
+  return webhook
+}
+
 func (this *Webhook) SetDebug() {
   this.Debug = true
+  if this.PicPay != nil {
+    this.PicPay.Debug = true
+  }
+}
+
+// Verify check the x-seller-token header sent by PicPay against the saller token
+func (this *Webhook) Verify(sellerToken string) error {
+  if !api.SecureCompare(this.SallerToken, sellerToken) {
+    return api.NewWebhookAuthError(api.GatewayPicPay, "invalid x-seller-token")
+  }
+  return nil
+}
+
+// ParseAndCheckStatus verify the seller token, parse the callback body and
+// load the real transaction status from PicPay. The callback don't carry
+// the status, so Parse alone always returns PicPayCreated.
+func (this *Webhook) ParseAndCheckStatus(sellerToken string, body []byte) (*WebhookData, error) {
+
+  if err := this.Verify(sellerToken); err != nil {
+    return nil, err
+  }
+
+  data, err := this.Parse(body)
+
+  if err != nil {
+    return nil, err
+  }
+
+  if err := this.CheckStatus(data); err != nil {
+    return nil, err
+  }
+
+  return data, nil
+}
+
+// CheckStatus load the real transaction status from PicPay to a parsed callback
+func (this *Webhook) CheckStatus(data *WebhookData) error {
+
+  if this.PicPay == nil {
+    return errors.New("PicPay client is required to check status")
+  }
+
+  result, err := this.PicPay.CheckStatus(data.ReferenceId)
+
+  if err != nil {
+    if this.PicPay.HasValidationError {
+      this.HasValidationError = true
+      this.ValidationErrors = this.PicPay.ValidationErrors
+    }
+    return err
+  }
+
+  transaction := result.Transaction
+
+  if len(transaction.ReferenceId) == 0 {
+    transaction.ReferenceId = data.ReferenceId
+  }
+
+  if len(transaction.AuthorizationId) == 0 {
+    transaction.AuthorizationId = data.AuthorizationId
+  }
+
+  // resolve cancelled status by cancellationId
+  transaction.GetPayZenSOAPStatus()
+
+  data.AuthorizationId = transaction.AuthorizationId
+  data.Response.Transaction = transaction
+  data.Response.Request = result.Request
+  data.Response.Response = result.Response
+
+  return nil
 }
 
+// Parse only decode the callback body, use ParseAndCheckStatus to get the real status
 func (this *Webhook) Parse(body []byte) (*WebhookData, error) {
 
 	jsonMap, err := this.JsonParser.JsonBytesToMap(body)
@@ -0,0 +1,70 @@
+package picpay
+
+import (
+  "fmt"
+  "net/http"
+  "github.com/mobilemindtec/go-payments/api"
+)
+
+type WebhookCallback func(r *http.Request, data *WebhookData) error
+
+// WebhookHandler is a http.Handler to PicPay callbacks. On beego, register
+// with web.Handler(path, handler) or call ServeHTTP(ctx.ResponseWriter, ctx.Request).
+//
+// The callback don't carry the status, so the handler check the real status
+// before call the user callback. Invalid x-seller-token is replied with 401,
+// invalid body with 400, check status and callback errors with 500 so PicPay
+// send the callback again.
+type WebhookHandler struct {
+  Webhook *Webhook
+  Callback WebhookCallback
+  MaxBodySize int64
+}
+
+func NewWebhookHandler(webhook *Webhook, callback WebhookCallback) *WebhookHandler {
+  return &WebhookHandler{ Webhook: webhook, Callback: callback, MaxBodySize: api.DefaultWebhookMaxBodySize }
+}
+
+func (this *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+  body, err := api.ReadWebhookBody(w, r, this.MaxBodySize)
+
+  if err != nil {
+    api.WriteWebhookError(w, err, http.StatusBadRequest)
+    return
+  }
+
+  // copy per request, validation state is kept on webhook and client
+  webhook := *this.Webhook
+  if webhook.PicPay != nil {
+    picpay := *webhook.PicPay
+    webhook.PicPay = &picpay
+  }
+
+  if err := webhook.Verify(r.Header.Get(SellerTokenHeader)); err != nil {
+    api.WriteWebhookError(w, err, http.StatusUnauthorized)
+    return
+  }
+
+  data, err := webhook.Parse(body)
+
+  if err != nil {
+    api.WriteWebhookError(w, err, http.StatusBadRequest)
+    return
+  }
+
+  if err := webhook.CheckStatus(data); err != nil {
+    if webhook.Debug {
+      fmt.Println("**** PicPay.WebhookHandler: ", err)
+    }
+    api.WriteWebhookError(w, err, http.StatusInternalServerError)
+    return
+  }
+
+  if err := this.Callback(r, data); err != nil {
+    api.WriteWebhookError(w, err, http.StatusInternalServerError)
+    return
+  }
+
+  api.WriteWebhookAck(w)
+}
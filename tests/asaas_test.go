@@ -1,9 +1,14 @@
 package gopayments
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-payments/asaas"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -396,6 +401,210 @@ func TestAsaasPaymentRefund(t *testing.T) {
 	}
 }
 
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentUpdate
+func TestAsaasPaymentUpdate(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+	Asaas.Debug = true
+
+	id, _ := CacheClient.Get("PaymentId").Result()
+
+	dueDate := time.Now().AddDate(0, 0, 7)
+
+	update := asaas.NewPaymentUpdate(id).SetDueDate(dueDate)
+	update.Value = 15
+	update.Fine = asaas.NewFine(2)
+	update.Interest = asaas.NewInterest(1)
+	update.Discount = asaas.NewDiscount(1, 0, asaas.DiscountFixed)
+
+	result, err := Asaas.PaymentUpdate(update)
+
+	if err != nil {
+		t.Errorf("Erro ao alterar payment: %v", err)
+		return
+	}
+
+	if result.Error {
+		t.Errorf("Erro ao alterar payment: %v, %v", result.Message, result.ErrorsToMap())
+		return
+	}
+
+	if result.DueDate != asaas.DateFormat(dueDate) {
+		t.Errorf("DueDate expected: %v, Received %v", asaas.DateFormat(dueDate), result.DueDate)
+		return
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentRestore
+func TestAsaasPaymentRestore(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+	Asaas.Debug = true
+
+	id, _ := CacheClient.Get("PaymentId").Result()
+
+	result, err := Asaas.PaymentRestore(id)
+
+	if err != nil {
+		t.Errorf("Erro ao restaurar payment: %v", err)
+		return
+	}
+
+	if result.Error {
+		t.Errorf("Erro ao restaurar payment: %v", result.Message)
+		return
+	}
+
+	if result.Deleted {
+		t.Errorf("Delete false expected, but is not")
+		return
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentCapture
+func TestAsaasPaymentCapture(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+	Asaas.Debug = true
+
+	customerId, _ := CacheClient.Get("ClientId").Result()
+
+	payment := asaas.NewPaymentWithCard(customerId, GenUUID(), 10)
+	payment.AuthorizeOnly = true
+
+	fillAsaasCard(payment)
+
+	result, err := Asaas.PaymentCreate(payment)
+
+	if err != nil {
+		t.Errorf("Erro ao criar payment: %v", err)
+		return
+	}
+
+	if result.Status != api.AsaasAuthorized {
+		t.Errorf("Status expected: %v, Received %v", api.AsaasAuthorized, result.Status)
+		return
+	}
+
+	result, err = Asaas.PaymentCapture(result.Id)
+
+	if err != nil {
+		t.Errorf("Erro ao capturar payment: %v", err)
+		return
+	}
+
+	if result.Status != api.AsaasConfirmed {
+		t.Errorf("Status expected: %v, Received %v", api.AsaasConfirmed, result.Status)
+		return
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentRefundPartial
+func TestAsaasPaymentRefundPartial(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+	Asaas.Debug = true
+
+	id, _ := CacheClient.Get("PaymentId").Result()
+
+	result, err := Asaas.PaymentRefundPartial(id, 2, "cancelamento parcial do pedido")
+
+	if err != nil {
+		t.Errorf("Erro ao devolver payment: %v", err)
+		return
+	}
+
+	if result.Error {
+		t.Errorf("Erro ao devolver payment: %v", result.Message)
+		return
+	}
+
+	result, err = Asaas.PaymentRefunds(id)
+
+	if err != nil {
+		t.Errorf("Erro ao listar estornos: %v", err)
+		return
+	}
+
+	if len(result.RefundResults.Data) == 0 {
+		t.Errorf("Expected refunds, but not have")
+		return
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentRefundSplits
+func TestAsaasPaymentRefundSplits(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+
+	refund := asaas.NewPaymentRefund("pay_xxx", 50, "estorno parcial").
+		AddSplitRefund("sp_1", 30).
+		AddSplitRefund("sp_2", 30)
+
+	if _, err := Asaas.PaymentRefundWith(refund); err == nil {
+		t.Errorf("Erro esperado, splits maior que o estorno")
+		return
+	}
+
+	refund.SplitRefunds[1].Value = 20
+
+	if refund.GetSplitRefundsValue() != 50 {
+		t.Errorf("Total de splits esperado 50, encontrado %v", refund.GetSplitRefundsValue())
+		return
+	}
+
+	data, _ := json.Marshal(refund)
+
+	if !strings.Contains(string(data), `"splitRefunds":[{"id":"sp_1","value":30}`) {
+		t.Errorf("splitRefunds esperado no body: %v", string(data))
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasSplitReversal
+func TestAsaasSplitReversal(t *testing.T) {
+
+	body := `{"id": "pay_xxx", "status": "REFUND_IN_PROGRESS", "value": 100,
+		"split": [
+			{"id": "sp_1", "walletId": "w_1", "totalValue": 60, "status": "PENDING"},
+			{"id": "sp_2", "walletId": "w_2", "totalValue": 30, "status": "PENDING"}],
+		"refunds": [
+			{"status": "PENDING", "value": 50, "refundedSplits": [{"id": "sp_1", "value": 30, "done": false}]},
+			{"status": "DONE", "value": 10, "refundedSplits": [{"id": "sp_2", "value": 3, "done": true}]},
+			{"status": "CANCELLED", "value": 40, "refundedSplits": [{"id": "sp_2", "value": 12, "done": false}]}]}`
+
+	result := asaas.NewResponse()
+
+	if err := json.Unmarshal([]byte(body), result); err != nil {
+		t.Errorf("Erro ao ler payment: %v", err)
+		return
+	}
+
+	result.BuildStatus()
+
+	if !result.IsRefundInProgress() || result.Status != api.AsaasRefundInProgress {
+		t.Errorf("Refund in progress expected, received %v", result.StatusText)
+	}
+
+	if result.GetRefundedValue() != 60 {
+		t.Errorf("Refunded value expected: 60, Received %v", result.GetRefundedValue())
+	}
+
+	reversals := result.GetSplitReversal()
+
+	if len(reversals) != 2 {
+		t.Errorf("Expected 2 split reversals, received %v", len(reversals))
+		return
+	}
+
+	if reversals[0].WalletId != "w_1" || reversals[0].RefundedValue != 30 || reversals[0].RemainingValue != 30 || reversals[0].Done {
+		t.Errorf("Unexpected reversal of w_1: %+v", reversals[0])
+	}
+
+	if reversals[1].RefundedValue != 3 || reversals[1].RemainingValue != 27 || !reversals[1].Done {
+		t.Errorf("Unexpected reversal of w_2: %+v", reversals[1])
+	}
+}
+
 // go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentGet
 func TestAsaasPaymentGet(t *testing.T) {
 
@@ -605,6 +814,31 @@ func TestAsaasInstallmentRefund(t *testing.T) {
 	}
 }
 
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasInstallmentRefundWith
+func TestAsaasInstallmentRefundWith(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+
+	if _, err := Asaas.InstallmentRefundWith(asaas.NewPaymentRefund("", 10, "")); err == nil {
+		t.Errorf("Erro esperado, parcelamento sem id")
+		return
+	}
+
+	refund := asaas.NewPaymentRefund("ins_xxx", 50, "estorno parcial").
+		AddSplitRefund("sp_1", 60)
+
+	if _, err := Asaas.InstallmentRefundWith(refund); err == nil {
+		t.Errorf("Erro esperado, splits maior que o estorno")
+		return
+	}
+
+	data, _ := json.Marshal(refund)
+
+	if !strings.Contains(string(data), `"value":50`) || !strings.Contains(string(data), `"description":"estorno parcial"`) {
+		t.Errorf("value e description esperados no body: %v", string(data))
+	}
+}
+
 // go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaaTokenCreate
 func TestAsaaTokenCreate(t *testing.T) {
 
@@ -1377,3 +1611,107 @@ func TestAsaasWallets(t *testing.T) {
 		return
 	}
 }
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasWebhookVerify
+func TestAsaasWebhookVerify(t *testing.T) {
+
+	webhook := asaas.NewDefaultWebhook().
+		SetAuthToken("main-token").
+		AddAccountToken("sub-account", "sub-token")
+
+	if err := webhook.AddAllowedIP("52.67.12.206", "18.230.8.0/24"); err != nil {
+		t.Errorf("Erro ao adicionar ip: %v", err)
+		return
+	}
+
+	if err := webhook.Verify("", "main-token", "52.67.12.206:4432"); err != nil {
+		t.Errorf("Token esperado válido: %v", err)
+		return
+	}
+
+	if err := webhook.Verify("sub-account", "sub-token", "18.230.8.10"); err != nil {
+		t.Errorf("Token esperado válido: %v", err)
+		return
+	}
+
+	if err := webhook.Verify("sub-account", "main-token", "18.230.8.10"); !api.IsWebhookAuthError(err) {
+		t.Errorf("Erro de autenticação esperado, encontrado %v", err)
+		return
+	}
+
+	if err := webhook.Verify("", "main-token", "10.0.0.1"); !api.IsWebhookAuthError(err) {
+		t.Errorf("Erro de autenticação esperado, encontrado %v", err)
+		return
+	}
+
+	body := []byte(`{"event": "PAYMENT_RECEIVED", "payment": {"id": "pay_123", "status": "RECEIVED"}}`)
+
+	if _, err := webhook.ParseAndVerify("", "forged", "52.67.12.206", body); !api.IsWebhookAuthError(err) {
+		t.Errorf("Erro de autenticação esperado, encontrado %v", err)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasWebhookHandler
+func TestAsaasWebhookHandler(t *testing.T) {
+
+	webhook := asaas.NewDefaultWebhook().SetAuthToken("main-token")
+
+	var received *asaas.WebhookData
+
+	handler := asaas.NewWebhookHandler(webhook, func(r *http.Request, data *asaas.WebhookData) error {
+		received = data
+		return nil
+	})
+
+	body := `{"event": "PAYMENT_RECEIVED", "payment": {"id": "pay_123", "status": "RECEIVED"}}`
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook/asaas", strings.NewReader(body))
+	request.Header.Set(asaas.WebhookAccessTokenHeader, "forged")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized || received != nil {
+		t.Errorf("Status 401 esperado, encontrado %v", recorder.Code)
+		return
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/webhook/asaas", strings.NewReader(body))
+	request.Header.Set(asaas.WebhookAccessTokenHeader, "main-token")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status 200 esperado, encontrado %v", recorder.Code)
+		return
+	}
+
+	if received == nil || received.Response.Id != "pay_123" {
+		t.Errorf("Callback não recebeu o pagamento")
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestAsaasPaymentsIter
+func TestAsaasPaymentsIter(t *testing.T) {
+
+	Asaas := asaas.NewAsaas("pt-BR", AsaasAccessToken, AsaasApiMode)
+	Asaas.Debug = true
+
+	filter := asaas.NewDefaultFilter()
+	filter.Limit = 10
+
+	count := 0
+	for payment, err := range Asaas.PaymentsIter(context.Background(), filter, api.NewPageOptions().WithPrefetch(2)) {
+		if err != nil {
+			t.Errorf("Erro ao listar payments: %v", err)
+			return
+		}
+		if len(payment.Id) == 0 {
+			t.Errorf("Expected payment id")
+		}
+		count++
+	}
+
+	if count == 0 {
+		t.Errorf("Expected payments, but not have")
+	}
+}
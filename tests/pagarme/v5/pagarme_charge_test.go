@@ -0,0 +1,99 @@
+package v5
+
+import (
+	"encoding/json"
+	"testing"
+
+	pagarme "github.com/mobilemindtec/go-payments/pagarme/v5"
+	gopayments "github.com/mobilemindtec/go-payments/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5ChargePartialSplitValidation
+func TestPagarmev5ChargePartialSplitValidation(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeCharge("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+
+	result := Pagarme.CancelAmount("ch_xxx", 1000,
+		pagarme.NewSplit("rp_1", 600, pagarme.Flat),
+		pagarme.NewSplit("rp_2", 300, pagarme.Flat))
+
+	assert.True(t, result.IsLeft())
+	assert.Contains(t, result.UnwrapLeft().Errors, "Split")
+
+	// erros da chamada anterior não devem aparecer na próxima validação
+	result = Pagarme.CancelAmount("ch_xxx", 1000,
+		pagarme.NewSplit("", 700, pagarme.Flat),
+		pagarme.NewSplit("rp_2", 300, pagarme.Flat))
+
+	assert.True(t, result.IsLeft())
+	assert.Contains(t, result.UnwrapLeft().Errors, "Split[0].RecipientId")
+	assert.NotContains(t, result.UnwrapLeft().Errors, "Split")
+
+	Pagarme = pagarme.NewPagarmeCharge("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+
+	result = Pagarme.CaptureAmount("ch_xxx", "", 1000,
+		pagarme.NewSplit("rp_1", 70, pagarme.Percentage),
+		pagarme.NewSplit("rp_2", 20, pagarme.Percentage))
+
+	assert.True(t, result.IsLeft())
+	assert.Contains(t, result.UnwrapLeft().Errors, "Split")
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5ChargePartialBalance
+func TestPagarmev5ChargePartialBalance(t *testing.T) {
+
+	charge := new(pagarme.Charge)
+	err := json.Unmarshal([]byte(`{
+		"id": "ch_xxx", "amount": 1000, "paid_amount": 1000, "canceled_amount": 400, "status": "paid",
+		"last_transaction": {"status": "partial_refunded"}}`), charge)
+
+	assert.Nil(t, err)
+	assert.True(t, charge.IsPartialRefunded())
+	assert.False(t, charge.IsPartialCapture())
+	assert.Equal(t, int64(0), charge.GetCapturableAmount())
+	assert.Equal(t, int64(600), charge.GetRefundableAmount())
+
+	charge = new(pagarme.Charge)
+	err = json.Unmarshal([]byte(`{
+		"id": "ch_xxx", "amount": 1000, "status": "pending",
+		"last_transaction": {"status": "authorized_pending_capture"}}`), charge)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), charge.GetCapturableAmount())
+	assert.Equal(t, int64(1000), charge.GetRefundableAmount())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5ChargeCancelAmount
+func TestPagarmev5ChargeCancelAmount(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeCharge("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	chargeId := "ch_6nBKxMvSXIMZr5Oq"
+	result := Pagarme.CancelAmount(chargeId, 100)
+
+	assert.False(t, result.IsLeft())
+	if result.IsRight() {
+		charge := result.UnwrapRight().Data
+		assert.True(t, charge.IsPartialRefunded())
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5ChargeSplitBreakdown
+func TestPagarmev5ChargeSplitBreakdown(t *testing.T) {
+
+	charge := new(pagarme.Charge)
+	err := json.Unmarshal([]byte(`{
+		"id": "ch_xxx", "amount": 1000, "paid_amount": 1000, "status": "paid",
+		"last_transaction": {"status": "captured", "split": [
+			{"type": "percentage", "amount": 80, "recipient": {"id": "rp_1"}},
+			{"type": "percentage", "amount": 20, "recipient": {"id": "rp_2"}}]}}`), charge)
+
+	assert.Nil(t, err)
+	assert.Len(t, charge.GetSplits(), 2)
+
+	breakdown := charge.GetSplitBreakdown()
+	assert.Equal(t, int64(800), breakdown["rp_1"])
+	assert.Equal(t, int64(200), breakdown["rp_2"])
+}
@@ -86,3 +86,34 @@ func TestPagarmev5CustomerUpdate(t *testing.T) {
 			fmt.Print(c.Data.Id)
 		})
 }
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5CustomerAddresses
+func TestPagarmev5CustomerAddresses(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeCustomer("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	customerId := "cus_zBknRWwFWfK4RQxa"
+
+	address := pagarme.NewAddress()
+	address.State = "RS"
+	address.City = "Porto Alegre"
+	address.ZipCode = "90000000"
+	address.Line1 = "100, Rua Teste, Centro"
+
+	result := Pagarme.CreateAddress(customerId, address)
+
+	assert.False(t, result.IsLeft())
+	if result.IsLeft() {
+		return
+	}
+
+	addressId := result.UnwrapRight().Data.Id
+	assert.NotEmpty(t, addressId)
+
+	list := Pagarme.ListAddresses(customerId)
+	assert.False(t, list.IsLeft())
+
+	deleted := Pagarme.DeleteAddress(customerId, addressId)
+	assert.False(t, deleted.IsLeft())
+}
@@ -0,0 +1,50 @@
+package v5
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	pagarme "github.com/mobilemindtec/go-payments/pagarme/v5"
+	gopayments "github.com/mobilemindtec/go-payments/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5HookOrderRef
+func TestPagarmev5HookOrderRef(t *testing.T) {
+
+	hook := new(pagarme.Hook)
+	err := json.Unmarshal([]byte(`{
+		"id": "hook_xxx", "event": "charge.paid", "status": "failed", "attempts": 3,
+		"data": {"id": "ch_xxx", "order": {"id": "or_xxx"}}}`), hook)
+
+	assert.Nil(t, err)
+	assert.True(t, hook.IsFailed())
+	assert.Equal(t, "ch_xxx", hook.GetObjectId())
+	assert.Equal(t, "or_xxx", hook.GetOrderId())
+
+	hook = new(pagarme.Hook)
+	err = json.Unmarshal([]byte(`{
+		"id": "hook_yyy", "event": "order.paid", "status": "sent",
+		"data": {"id": "or_yyy"}}`), hook)
+
+	assert.Nil(t, err)
+	assert.False(t, hook.IsFailed())
+	assert.Equal(t, "or_yyy", hook.GetOrderId())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5HookRetryFailed
+func TestPagarmev5HookRetryFailed(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeHook("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	now := time.Now()
+	result := Pagarme.RetryFailedBetween(now.AddDate(0, 0, -1), now)
+
+	assert.False(t, result.IsLeft())
+	if result.IsRight() {
+		sweep := result.UnwrapRight().Data
+		assert.False(t, sweep.HasErrors())
+	}
+}
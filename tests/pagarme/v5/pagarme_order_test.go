@@ -1,6 +1,7 @@
 package v5
 
 import (
+	"github.com/mobilemindtec/go-payments/api"
 	pagarme "github.com/mobilemindtec/go-payments/pagarme/v5"
 	gopayments "github.com/mobilemindtec/go-payments/tests"
 	"github.com/stretchr/testify/assert"
@@ -60,3 +61,316 @@ func TestPagarmev5OrderGet(t *testing.T) {
 	assert.False(t, result.IsLeft())
 	assert.Truef(t, result.Right().NonEmpty(), "empty order response")
 }
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderOpenTab
+func TestPagarmev5OrderOpenTab(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	order := newOrder().AsOpen()
+	order.Payments = []*pagarme.Payment{}
+
+	result := Pagarme.Create(order)
+
+	if !assert.False(t, result.IsLeft()) {
+		return
+	}
+
+	orderId := result.UnwrapRight().Data.Id
+
+	itemResult := Pagarme.AddItem(orderId, pagarme.NewOrderItem(gopayments.GenUUID(), "second item", 500, 2))
+
+	if assert.False(t, itemResult.IsLeft()) {
+		assert.NotEmptyf(t, itemResult.UnwrapRight().Data.Id, "empty item id")
+	}
+
+	payment := pagarme.NewPayment(2000, pagarme.MethodCreditCard)
+	fillCreditCard(payment.CreditCard)
+
+	chargeResult := Pagarme.AddCharge(orderId, payment)
+	assert.False(t, chargeResult.IsLeft())
+
+	closeResult := Pagarme.Close(orderId, pagarme.OrderPaid)
+
+	if assert.False(t, closeResult.IsLeft()) {
+		assert.True(t, closeResult.UnwrapRight().Data.Closed)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderPaymentsRequired
+func TestPagarmev5OrderPaymentsRequired(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+
+	order := newOrder()
+	order.Payments = []*pagarme.Payment{}
+
+	assert.False(t, order.IsOpenRequested())
+
+	result := Pagarme.Create(order)
+
+	if assert.True(t, result.IsLeft()) {
+		assert.Contains(t, result.UnwrapLeft().Errors, "Payments")
+	}
+
+	order.AsOpen()
+	assert.True(t, order.IsOpenRequested())
+
+	order.Closed = true
+	assert.False(t, order.IsOpenRequested())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderDebitVoucherValidation
+func TestPagarmev5OrderDebitVoucherValidation(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+
+	order := newOrder()
+	order.Payments = []*pagarme.Payment{}
+	order.
+		AddPayment(1000, pagarme.MethodDebitCard).
+		WithDebitCard(func(debitCard *pagarme.DebitCard) {
+			debitCard.StatementDescriptor = "MMIND"
+			debitCard.CardToken = "token_xxx"
+			debitCard.Authentication = &pagarme.PaymentAuthentication{
+				Type:         pagarme.AuthenticationThreeDSecure,
+				ThreeDSecure: &pagarme.ThreeDSecure{Mpi: pagarme.MpiThirdParty, Cavv: "cavv"},
+			}
+		})
+
+	result := Pagarme.Create(order)
+
+	assert.True(t, result.IsLeft())
+	assert.Contains(t, result.UnwrapLeft().Errors, "ThreeDSecure")
+
+	order.Payments = []*pagarme.Payment{}
+	order.
+		AddPayment(1000, pagarme.MethodVoucher).
+		WithVoucher(func(voucher *pagarme.Voucher) {
+			voucher.StatementDescriptor = "MMIND"
+			voucher.Card.Number = "4901720080344448"
+			voucher.Card.HolderName = "Aardvark Silva"
+			voucher.Card.ExpMonth = 12
+			voucher.Card.ExpYear = 2028
+			voucher.Card.Cvv = "314"
+			fillBillingAddress(voucher.Card.BillingAddress)
+		})
+
+	result = Pagarme.Create(order)
+
+	assert.True(t, result.IsLeft())
+	assert.Contains(t, result.UnwrapLeft().Errors, "HolderDocument")
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderDebitCard
+func TestPagarmev5OrderDebitCard(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	order := newOrder()
+	order.Payments = []*pagarme.Payment{}
+	order.
+		AddPayment(1000, pagarme.MethodDebitCard).
+		WithDebitCard(func(debitCard *pagarme.DebitCard) {
+			creditCard := pagarme.NewCreditCard()
+			fillCreditCard(creditCard)
+			debitCard.StatementDescriptor = creditCard.StatementDescriptor
+			debitCard.Card = creditCard.Card
+			debitCard.Authentication = pagarme.NewAcquirerThreeDSecure("https://mobilemind.com.br/3ds")
+		})
+
+	result := Pagarme.Create(order)
+
+	if assert.False(t, result.IsLeft()) {
+		transaction := result.UnwrapRight().Data.GetLastTransaction().GetOr(nil)
+		if assert.NotNil(t, transaction) {
+			assert.Equal(t, api.PaymentTypeDebitCard, transaction.GetPaymentType())
+		}
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderDebitTransactionStatus
+func TestPagarmev5OrderDebitTransactionStatus(t *testing.T) {
+
+	transaction := &pagarme.LastTransaction{
+		TransactionType:         "debit_card",
+		Status:                  api.PagarmeV5Processing,
+		ThreeDAuthenticationUrl: "https://3ds.pagar.me/xxx",
+	}
+
+	assert.True(t, transaction.IsWaitingAuthentication())
+	assert.Equal(t, api.WaitingAuthorisation, transaction.GetPayZenSOAPStatus())
+
+	transaction.Status = api.PagarmeV5Captured
+	assert.False(t, transaction.IsWaitingAuthentication())
+	assert.Equal(t, api.Captured, transaction.GetPayZenSOAPStatus())
+
+	transaction.TransactionType = "voucher"
+	assert.Equal(t, api.PaymentTypeVoucher, transaction.GetPaymentType())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderMultiPaymentAmount
+func TestPagarmev5OrderMultiPaymentAmount(t *testing.T) {
+
+	order := newOrder()
+	order.Payments[0].Amount = 600
+	order.
+		AddPayment(400, pagarme.MethodCreditCard).
+		WithCreditCard(func(creditCard *pagarme.CreditCard) {
+			creditCard.StatementDescriptor = "MMIND"
+			creditCard.CardId = "card_xxx"
+		})
+
+	assert.True(t, order.IsMultiPayment())
+	assert.Len(t, order.GetCardPayments(), 2)
+	assert.True(t, order.IsPaymentsAmountValid())
+	assert.False(t, order.HasCardIdOrToken())
+
+	order.SetCardTokens("token_1")
+	assert.True(t, order.HasCardIdOrToken())
+	assert.Equal(t, "token_1", order.Payments[0].CreditCard.CardToken)
+	assert.Empty(t, order.Payments[0].CreditCard.Card.Number)
+
+	order.Payments[1].Amount = 300
+	assert.False(t, order.IsPaymentsAmountValid())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderMultiPaymentProgress
+func TestPagarmev5OrderMultiPaymentProgress(t *testing.T) {
+
+	order := pagarme.NewOrder()
+	order.Charges = []*pagarme.Charge{
+		{Id: "ch_1", Amount: 600, PaidAmount: 600, Status: pagarme.ChargePaid},
+		{Id: "ch_2", Amount: 400, Status: pagarme.ChargePending},
+	}
+
+	assert.True(t, order.IsPartiallyPaid())
+	assert.Equal(t, int64(600), order.GetPaidAmount())
+
+	order.Charges[1].Status = pagarme.ChargePaid
+	assert.True(t, order.IsFullyPaid())
+
+	order.Charges = nil
+	assert.Equal(t, pagarme.OrderUnpaid, order.GetPaymentProgress())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderMultiPaymentCapture
+func TestPagarmev5OrderMultiPaymentCapture(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	order := newOrder()
+	order.Payments = []*pagarme.Payment{}
+
+	for _, amount := range []int64{600, 400} {
+		order.
+			AddPayment(amount, pagarme.MethodCreditCard).
+			WithCreditCard(func(creditCard *pagarme.CreditCard) {
+				fillCreditCard(creditCard)
+				creditCard.OperationType = pagarme.AuthOnly
+			})
+	}
+
+	result := Pagarme.Create(order)
+
+	if !assert.False(t, result.IsLeft()) {
+		return
+	}
+
+	captureResult := Pagarme.CaptureAll(result.UnwrapRight().Data.Id)
+
+	if assert.False(t, captureResult.IsLeft()) {
+		assert.True(t, captureResult.UnwrapRight().Data.IsFullyPaid())
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderCardPixWaitingCapture
+func TestPagarmev5OrderCardPixWaitingCapture(t *testing.T) {
+
+	order := pagarme.NewOrder()
+	order.Charges = []*pagarme.Charge{
+		{Id: "ch_card", Amount: 600, Status: pagarme.ChargePending, PaymentMethod: pagarme.MethodCreditCard,
+			LastTransaction: &pagarme.LastTransaction{Status: api.PagarmeV5AuthorizedPendingCapture}},
+		{Id: "ch_pix", Amount: 400, Status: pagarme.ChargePending, PaymentMethod: pagarme.MethodPix,
+			LastTransaction: &pagarme.LastTransaction{Status: api.PagarmeV5WaitingPayment}},
+	}
+
+	charges := order.GetChargesWaitingCapture()
+
+	if assert.Len(t, charges, 1) {
+		assert.Equal(t, "ch_card", charges[0].Id)
+	}
+
+	order.Charges[0].Status = pagarme.ChargePaid
+	order.Charges[0].LastTransaction.Status = api.PagarmeV5Captured
+	assert.Empty(t, order.GetChargesWaitingCapture())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderCardPixCapture
+func TestPagarmev5OrderCardPixCapture(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	order := newOrder()
+	order.Payments = []*pagarme.Payment{}
+	order.
+		AddPayment(600, pagarme.MethodCreditCard).
+		WithCreditCard(func(creditCard *pagarme.CreditCard) {
+			fillCreditCard(creditCard)
+			creditCard.OperationType = pagarme.AuthOnly
+		})
+	order.AddPayment(400, pagarme.MethodPix)
+	order.Payments[1].Pix.ExpiresIn = 3600
+
+	result := Pagarme.Create(order)
+
+	if !assert.False(t, result.IsLeft()) {
+		return
+	}
+
+	captureResult := Pagarme.CaptureAll(result.UnwrapRight().Data.Id)
+
+	if assert.False(t, captureResult.IsLeft()) {
+		captured := captureResult.UnwrapRight().Data
+		assert.True(t, captured.IsPartiallyPaid())
+		assert.Empty(t, captured.GetChargesWaitingCapture())
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5OrderCancelAllWithFailedCharge
+func TestPagarmev5OrderCancelAllWithFailedCharge(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeOrder("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	order := newOrder()
+	order.Payments = []*pagarme.Payment{}
+
+	for _, number := range []string{"4000000000000010", "4000000000000028"} {
+		order.
+			AddPayment(500, pagarme.MethodCreditCard).
+			WithCreditCard(func(creditCard *pagarme.CreditCard) {
+				fillCreditCard(creditCard)
+				creditCard.Card.Number = number
+			})
+	}
+
+	result := Pagarme.Create(order)
+
+	if !assert.False(t, result.IsLeft()) {
+		return
+	}
+
+	// a cobrança recusada não impede o estorno da aprovada
+	cancelResult := Pagarme.CancelAll(result.UnwrapRight().Data.Id)
+
+	if assert.False(t, cancelResult.IsLeft()) {
+		for _, it := range cancelResult.UnwrapRight().Data.Charges {
+			assert.False(t, it.IsCancelable())
+		}
+	}
+}
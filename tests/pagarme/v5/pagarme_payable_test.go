@@ -0,0 +1,53 @@
+package v5
+
+import (
+	"testing"
+	"time"
+
+	pagarme "github.com/mobilemindtec/go-payments/pagarme/v5"
+	gopayments "github.com/mobilemindtec/go-payments/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5PayableSettlementCalendarTotals
+func TestPagarmev5PayableSettlementCalendarTotals(t *testing.T) {
+
+	payables := pagarme.Payables{
+		{RecipientId: "rp_2", PaymentDate: "2024-06-10T00:00:00Z", Amount: 1000, Fee: 50},
+		{RecipientId: "rp_1", PaymentDate: "2024-06-10T00:00:00Z", Amount: 2000, Fee: 80, AnticipationFee: 20},
+		{RecipientId: "rp_1", PaymentDate: "2024-06-10T00:00:00Z", Amount: 500, Fee: 10},
+		{RecipientId: "rp_1", PaymentDate: "2024-06-09T00:00:00Z", Amount: 300},
+		{RecipientId: "rp_1", PaymentDate: "2024-06-09T00:00:00Z", Amount: 700, Status: pagarme.PayablePaid},
+		{RecipientId: "rp_3", PaymentDate: "2024-06-11T00:00:00Z", Amount: 900, Status: pagarme.PayablePrepaid},
+	}
+
+	calendar := pagarme.NewSettlementCalendar(payables)
+
+	assert.Len(t, calendar, 3)
+
+	assert.Equal(t, "2024-06-09", calendar[0].Date)
+	assert.Equal(t, int64(300), calendar[0].NetAmount)
+
+	assert.Equal(t, "rp_1", calendar[1].RecipientId)
+	assert.Equal(t, 2, calendar[1].Count)
+	assert.Equal(t, int64(2500), calendar[1].Amount)
+	assert.Equal(t, int64(2390), calendar[1].NetAmount)
+
+	assert.Equal(t, "rp_2", calendar[2].RecipientId)
+	assert.Equal(t, int64(950), calendar[2].NetAmount)
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5PayableSettlementCalendar
+func TestPagarmev5PayableSettlementCalendar(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmePayable("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	query := pagarme.NewPayableQuery().
+		WithRecipient("rp_xxxxxxxxxxxxxxxx").
+		WithPaymentDate(time.Now(), time.Now().AddDate(0, 1, 0))
+
+	result := Pagarme.SettlementCalendar(query)
+
+	assert.False(t, result.IsLeft())
+}
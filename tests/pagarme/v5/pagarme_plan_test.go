@@ -111,3 +111,28 @@ func TestPagarmev5PlanDelete(t *testing.T) {
 
 	assert.False(t, result.IsLeft())
 }
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5PlanItems
+func TestPagarmev5PlanItems(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmePlan("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	planId := "plan_6wl3pk2HrxcrjdY7"
+
+	result := Pagarme.AddItem(planId, pagarme.NewPlanItem("Add-on", 1, 0, 990))
+
+	assert.False(t, result.IsLeft())
+	if result.IsLeft() {
+		return
+	}
+
+	item := result.UnwrapRight().Data
+	item.Description = "Add-on mensal"
+
+	updated := Pagarme.UpdateItem(planId, item)
+	assert.False(t, updated.IsLeft())
+
+	removed := Pagarme.RemoveItem(planId, item.Id)
+	assert.False(t, removed.IsLeft())
+}
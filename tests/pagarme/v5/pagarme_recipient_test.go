@@ -0,0 +1,47 @@
+package v5
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	pagarme "github.com/mobilemindtec/go-payments/pagarme/v5"
+	gopayments "github.com/mobilemindtec/go-payments/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5RecipientBalanceOperationAnticipation
+func TestPagarmev5RecipientBalanceOperationAnticipation(t *testing.T) {
+
+	operation := new(pagarme.BalanceOperation)
+	err := json.Unmarshal([]byte(`{
+		"id": "bo_xxx", "type": "anticipation", "amount": 10000, "fee": 0,
+		"movement_object": {"object": "anticipation", "id": "ant_xxx", "amount": 10000,
+			"anticipation_fee": 350, "fee": 100, "status": "approved", "timeframe": "start"}}`), operation)
+
+	assert.Nil(t, err)
+	assert.True(t, operation.IsAnticipation())
+	assert.Equal(t, int64(350), operation.GetAnticipationFee())
+	assert.Equal(t, pagarme.TimeframeStart, operation.MovementObject.Timeframe)
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5RecipientAnticipation
+func TestPagarmev5RecipientAnticipation(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeRecipient("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	recipientId := "rp_xxxxxxxxxxxxxxxx"
+	anticipation := pagarme.NewAnticipation(10000, pagarme.TimeframeStart, time.Now().AddDate(0, 0, 1))
+
+	estimate := Pagarme.EstimateAnticipation(recipientId, anticipation)
+
+	assert.False(t, estimate.IsLeft())
+	if estimate.IsLeft() {
+		return
+	}
+
+	result := Pagarme.CreateAnticipation(recipientId, anticipation)
+
+	assert.False(t, result.IsLeft())
+}
@@ -1,6 +1,7 @@
 package v5
 
 import (
+	"encoding/json"
 	gopayments "github.com/mobilemindtec/go-payments/tests"
 	"testing"
 	"time"
@@ -182,3 +183,127 @@ func TestPagarmev5SubscriptionEditWithCardId(t *testing.T) {
 
 	assert.False(t, result.IsLeft())
 }
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5SubscriptionDiscountJson
+func TestPagarmev5SubscriptionDiscountJson(t *testing.T) {
+
+	data, err := json.Marshal(pagarme.NewDiscount(10, pagarme.Percentage, 3))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"value":10,"cycles":3,"discount_type":"percentage"}`, string(data))
+
+	data, err = json.Marshal(pagarme.NewIncrement(500, pagarme.Flat, 0))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"value":500,"increment_type":"flat"}`, string(data))
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5SubscriptionNilOperation
+func TestPagarmev5SubscriptionNilOperation(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeSubscription("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+
+	discount := Pagarme.AddDiscount("sub_xxx", nil)
+
+	if assert.True(t, discount.IsLeft()) {
+		assert.Contains(t, discount.UnwrapLeft().Errors, "Discount")
+	}
+
+	increment := Pagarme.AddIncrement("sub_xxx", nil)
+
+	if assert.True(t, increment.IsLeft()) {
+		assert.Contains(t, increment.UnwrapLeft().Errors, "Increment")
+		assert.NotContains(t, increment.UnwrapLeft().Errors, "Discount")
+	}
+
+	itemDiscount := Pagarme.AddItemDiscount("sub_xxx", "si_xxx", nil)
+
+	if assert.True(t, itemDiscount.IsLeft()) {
+		assert.Contains(t, itemDiscount.UnwrapLeft().Errors, "Discount")
+	}
+
+	itemIncrement := Pagarme.AddItemIncrement("sub_xxx", "si_xxx", nil)
+
+	if assert.True(t, itemIncrement.IsLeft()) {
+		assert.Contains(t, itemIncrement.UnwrapLeft().Errors, "Increment")
+	}
+
+	usage := Pagarme.ReportUsage("sub_xxx", "si_xxx", nil)
+
+	if assert.True(t, usage.IsLeft()) {
+		assert.Contains(t, usage.UnwrapLeft().Errors, "Usage")
+		assert.NotContains(t, usage.UnwrapLeft().Errors, "Increment")
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5SubscriptionDiscounts
+func TestPagarmev5SubscriptionDiscounts(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeSubscription("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	subscriptionId := "sub_pG6KjZ0iOivgNRw2"
+
+	invalid := Pagarme.AddDiscount(subscriptionId, pagarme.NewDiscount(150, pagarme.Percentage, 1))
+	assert.True(t, invalid.IsLeft())
+
+	result := Pagarme.AddDiscount(subscriptionId, pagarme.NewDiscount(10, pagarme.Percentage, 2))
+
+	assert.False(t, result.IsLeft())
+	if result.IsLeft() {
+		return
+	}
+
+	discountId := result.UnwrapRight().Data.Id
+
+	list := Pagarme.ListDiscounts(subscriptionId)
+	assert.False(t, list.IsLeft())
+
+	deleted := Pagarme.DeleteDiscount(subscriptionId, discountId)
+	assert.False(t, deleted.IsLeft())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5SubscriptionUsages
+func TestPagarmev5SubscriptionUsages(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeSubscription("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	subscriptionId := "sub_pG6KjZ0iOivgNRw2"
+	itemId := "si_1nbWDv1fLcn5XYkw"
+
+	result := Pagarme.ReportUsage(subscriptionId, itemId, pagarme.NewUsage(10, "api calls", time.Now()))
+
+	assert.False(t, result.IsLeft())
+	if result.IsLeft() {
+		return
+	}
+
+	usageId := result.UnwrapRight().Data.Id
+
+	list := Pagarme.ListUsages(subscriptionId, itemId, pagarme.NewUsageQuery())
+	assert.False(t, list.IsLeft())
+
+	deleted := Pagarme.DeleteUsage(subscriptionId, itemId, usageId)
+	assert.False(t, deleted.IsLeft())
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmev5SubscriptionCycles
+func TestPagarmev5SubscriptionCycles(t *testing.T) {
+
+	Pagarme := pagarme.NewPagarmeSubscription("pt-BR", pagarme.NewAuthentication(gopayments.SecretKey, gopayments.PublicKey), "")
+	Pagarme.DebugOn()
+
+	subscriptionId := "sub_pG6KjZ0iOivgNRw2"
+
+	cycles := Pagarme.ListCycles(subscriptionId, pagarme.NewCycleQuery())
+
+	assert.False(t, cycles.IsLeft())
+
+	result := Pagarme.UpdateBillingDate(subscriptionId, time.Now().AddDate(0, 0, 10))
+
+	assert.False(t, result.IsLeft())
+	if result.IsRight() {
+		assert.NotEmpty(t, result.UnwrapRight().Data.NextBillingAt)
+	}
+}
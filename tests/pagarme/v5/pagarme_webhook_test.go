@@ -0,0 +1,152 @@
+package v5
+
+import (
+	"encoding/base64"
+	"github.com/mobilemindtec/go-payments/api"
+	pagarme "github.com/mobilemindtec/go-payments/pagarme/v5"
+	"testing"
+)
+
+const chargePaidWebhook = `{
+	"id": "hook_RyEKQO789TRpZjv5",
+	"account": {"id": "acc_jZkdN857et650oNv", "name": "Lojinha"},
+	"type": "charge.paid",
+	"created_at": "2024-05-10T14:16:12.957Z",
+	"data": {
+		"id": "ch_d22356Jf4WuGr8no",
+		"code": "ABC123",
+		"amount": 1500,
+		"paid_amount": 1500,
+		"status": "paid",
+		"payment_method": "credit_card",
+		"last_transaction": {"id": "tran_opAqDj2390S1lKQO", "status": "captured", "amount": 1500}
+	}
+}`
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmeWebhookTyped
+func TestPagarmeWebhookTyped(t *testing.T) {
+
+	webhook := pagarme.NewDefaultWebhook().SetBasicAuth("user", "pass")
+
+	authorization := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+
+	data, err := webhook.ParseAndVerify(authorization, []byte(chargePaidWebhook))
+
+	if err != nil {
+		t.Errorf("error on parse webhook: %v", err)
+		return
+	}
+
+	charge, ok := data.Charge()
+
+	if !ok {
+		t.Errorf("expected charge webhook, found %T", data.Object)
+		return
+	}
+
+	if charge.Data.Id != "ch_d22356Jf4WuGr8no" || charge.Data.LastTransaction.Status != api.PagarmeV5Captured {
+		t.Errorf("unexpected charge %v", charge.Data)
+		return
+	}
+
+	if _, ok := data.Order(); ok {
+		t.Errorf("charge webhook decoded as order")
+		return
+	}
+
+	if data.PaymentStatus != api.PaymentPaid {
+		t.Errorf("expected status paid, found %v", data.PaymentStatus)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmeWebhookVerify
+func TestPagarmeWebhookVerify(t *testing.T) {
+
+	webhook := pagarme.NewDefaultWebhook().SetBasicAuth("user", "pass")
+
+	forged := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:wrong"))
+
+	if err := webhook.Verify(forged); !api.IsWebhookAuthError(err) {
+		t.Errorf("expected auth error, found %v", err)
+		return
+	}
+
+	if _, err := webhook.ParseAndVerify("", []byte(chargePaidWebhook)); !api.IsWebhookAuthError(err) {
+		t.Errorf("expected auth error, found %v", err)
+	}
+}
+
+const checkoutCanceledWebhook = `{
+	"id": "hook_Qz8aE2lDsxcnW1vB",
+	"type": "checkout.canceled",
+	"created_at": "2024-05-10T14:16:12.957Z",
+	"data": {
+		"id": "chk_9XlWvAxSq7C8aBNd",
+		"amount": 1500,
+		"status": "canceled",
+		"payment_url": "https://pagar.me/checkout/chk_9XlWvAxSq7C8aBNd",
+		"accepted_payment_methods": ["credit_card", "pix"]
+	}
+}`
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmeWebhookCheckout
+func TestPagarmeWebhookCheckout(t *testing.T) {
+
+	data, err := pagarme.NewDefaultWebhook().Parse([]byte(checkoutCanceledWebhook))
+
+	if err != nil {
+		t.Errorf("error on parse webhook: %v", err)
+		return
+	}
+
+	checkout, ok := data.Checkout()
+
+	if !ok {
+		t.Errorf("expected checkout webhook, found %T", data.Object)
+		return
+	}
+
+	if checkout.Data.PaymentUrl == "" || len(checkout.Data.AcceptedPaymentMethods) != 2 {
+		t.Errorf("unexpected checkout %v", checkout.Data)
+		return
+	}
+
+	if data.PaymentStatus != api.PaymentCancelled {
+		t.Errorf("expected status cancelled, found %v", data.PaymentStatus)
+	}
+}
+
+const recipientUpdatedWebhook = `{
+	"id": "hook_aB3dE5fG7hJ9kL1m",
+	"type": "recipient.updated",
+	"created_at": "2024-05-10T14:16:12.957Z",
+	"data": {
+		"id": "rp_xxxxxxxxxxxxxxxx",
+		"name": "Loja",
+		"status": "affiliation",
+		"kyc_details": {"status": "approved"},
+		"default_bank_account": {"id": "ba_xxx", "account_number": "12345", "status": "active"}
+	}
+}`
+
+// go test -v  github.com/mobilemindtec/go-payments/tests/pagarme/v5 -run TestPagarmeWebhookRecipientStatus
+func TestPagarmeWebhookRecipientStatus(t *testing.T) {
+
+	data, err := pagarme.NewDefaultWebhook().Parse([]byte(recipientUpdatedWebhook))
+
+	if err != nil {
+		t.Errorf("error on parse webhook: %v", err)
+		return
+	}
+
+	status, pending, ok := data.RecipientStatus()
+
+	if !ok || status != pagarme.RecipientAffiliation {
+		t.Errorf("unexpected recipient status %v", status)
+		return
+	}
+
+	if len(pending) != 1 || pending[0] != pagarme.RequirementActivation {
+		t.Errorf("unexpected recipient pending requirements %v", pending)
+	}
+}
@@ -0,0 +1,116 @@
+package gopayments
+
+import (
+	"context"
+	"errors"
+	"github.com/mobilemindtec/go-payments/api"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestPages(pages int, size int, fetched *int32) api.PageFetcher[int] {
+	return func(ctx context.Context, page int) ([]int, bool, error) {
+		atomic.AddInt32(fetched, 1)
+		if page >= pages {
+			return []int{}, false, nil
+		}
+		items := []int{}
+		for i := 0; i < size; i++ {
+			items = append(items, page*size+i)
+		}
+		return items, page < pages-1, nil
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestPaginate
+func TestPaginate(t *testing.T) {
+
+	var fetched int32
+	count := 0
+
+	for item, err := range api.Paginate(context.Background(), newTestPages(3, 10, &fetched)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item != count {
+			t.Errorf("item expected: %v, received %v", count, item)
+		}
+		count++
+	}
+
+	if count != 30 || fetched != 3 {
+		t.Errorf("expected 30 items in 3 pages, received %v items in %v pages", count, fetched)
+	}
+
+	// para no meio da primeira página sem buscar a próxima
+	fetched = 0
+	for item := range api.Paginate(context.Background(), newTestPages(3, 10, &fetched)) {
+		if item == 5 {
+			break
+		}
+	}
+
+	if fetched != 1 {
+		t.Errorf("expected lazy fetch of 1 page, fetched %v", fetched)
+	}
+
+	fetched = 0
+	count = 0
+	for _, err := range api.Paginate(context.Background(), newTestPages(5, 10, &fetched), api.NewPageOptions().WithPrefetch(2)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+	}
+
+	if count != 50 {
+		t.Errorf("expected 50 items with prefetch, received %v", count)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestPaginateStop
+func TestPaginateStop(t *testing.T) {
+
+	fail := errors.New("page error")
+
+	fetch := func(ctx context.Context, page int) ([]int, bool, error) {
+		if page == 1 {
+			return nil, false, fail
+		}
+		return []int{1, 2}, true, nil
+	}
+
+	count := 0
+	var last error
+	for _, err := range api.Paginate(context.Background(), fetch) {
+		if err != nil {
+			last = err
+			continue
+		}
+		count++
+	}
+
+	if count != 2 || !errors.Is(last, fail) {
+		t.Errorf("expected 2 items and page error, received %v items and %v", count, last)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count = 0
+	last = nil
+	for _, err := range api.Paginate(ctx, func(ctx context.Context, page int) ([]int, bool, error) {
+		return []int{1, 2, 3}, true, nil
+	}) {
+		if err != nil {
+			last = err
+			continue
+		}
+		count++
+		cancel()
+	}
+
+	if count != 1 || !errors.Is(last, context.Canceled) {
+		t.Errorf("expected stop on cancel after 1 item, received %v items and %v", count, last)
+	}
+}
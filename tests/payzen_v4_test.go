@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/mobilemindtec/go-payments/api"
 	"github.com/mobilemindtec/go-payments/payzen/v4"
-	_ "net/url"
-	_ "strings"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 )
@@ -489,3 +491,75 @@ func TestPayZenV4NotificacaoFormData(t *testing.T) {
 	  t.Errorf(string(jsonData))
 	*/
 }
+
+// go test -v github.com/mobilemindtec/go-payments/tests -run TestPayZenV4WebhookHandler
+func TestPayZenV4WebhookHandler(t *testing.T) {
+
+	krAnswer := `{"shopId":"31187067","orderStatus":"PAID","orderDetails":{"orderId":"a218c526"}}`
+
+	form := url.Values{}
+	form.Set("kr-hash-key", v4.HashKeyPassword)
+	form.Set("kr-hash-algorithm", "sha256_hmac")
+	form.Set("kr-answer-type", "V4/Payment")
+	form.Set("kr-answer", krAnswer)
+	form.Set("kr-hash", v4.GenerateSignatureFromBody("ipn-password", krAnswer))
+
+	webhook := v4.NewDefaultWebhook().SetKeys("ipn-password", "hmac-key")
+
+	called := false
+	handler := v4.NewWebhookHandler(webhook, func(r *http.Request, data *v4.WebhookData) error {
+		called = true
+		return nil
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook/payzen", strings.NewReader(form.Encode()))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !called {
+		t.Errorf("Status 200 esperado, encontrado %v", recorder.Code)
+		return
+	}
+
+	form.Set("kr-hash", v4.GenerateSignatureFromBody("other-password", krAnswer))
+	called = false
+
+	request = httptest.NewRequest(http.MethodPost, "/webhook/payzen", strings.NewReader(form.Encode()))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized || called {
+		t.Errorf("Status 401 esperado, encontrado %v", recorder.Code)
+		return
+	}
+
+	// segmento sem = não pode derrubar o handler
+	request = httptest.NewRequest(http.MethodPost, "/webhook/payzen", strings.NewReader("x"))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code < 400 || recorder.Code > 499 || called {
+		t.Errorf("Status 4xx esperado, encontrado %v", recorder.Code)
+		return
+	}
+
+	// kr-answer com = e & deve manter a assinatura
+	krAnswer = `{"shopId":"31187067","orderStatus":"PAID","orderDetails":{"orderId":"a=1&b=2"}}`
+	form.Set("kr-answer", krAnswer)
+	form.Set("kr-hash", v4.GenerateSignatureFromBody("ipn-password", krAnswer))
+
+	var orderId string
+	handler = v4.NewWebhookHandler(webhook, func(r *http.Request, data *v4.WebhookData) error {
+		called = true
+		orderId = data.Answer.OrderDetails.OrderId
+		return nil
+	})
+
+	request = httptest.NewRequest(http.MethodPost, "/webhook/payzen", strings.NewReader(form.Encode()))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !called || orderId != "a=1&b=2" {
+		t.Errorf("Status 200 esperado, encontrado %v, order id %v", recorder.Code, orderId)
+	}
+}
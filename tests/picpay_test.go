@@ -96,3 +96,44 @@ func TestPicPayCheckCancel(t *testing.T) {
 	}
 
 }
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestPicPayWebhookVerify
+func TestPicPayWebhookVerify(t *testing.T) {
+
+	webhook := picpay.NewWebhookWithPicPay("pt-BR", Token, "seller-token")
+
+	if err := webhook.Verify("seller-token"); err != nil {
+		t.Errorf("Token esperado válido: %v", err)
+		return
+	}
+
+	_, err := webhook.ParseAndCheckStatus("invalid-token", []byte(`{"referenceId": "123"}`))
+
+	if !api.IsWebhookAuthError(err) {
+		t.Errorf("Erro de autenticação esperado, encontrado %v", err)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestPicPayWebhookCheckStatus
+func TestPicPayWebhookCheckStatus(t *testing.T) {
+
+	webhook := picpay.NewWebhookWithPicPay("pt-BR", Token, SallerToken)
+	webhook.SetDebug()
+
+	referenceId, _ := CacheClient.Get("ReferenceId").Result()
+	body := fmt.Sprintf(`{"referenceId": "%v"}`, referenceId)
+
+	data, err := webhook.ParseAndCheckStatus(SallerToken, []byte(body))
+
+	if err != nil {
+		t.Errorf("Erro ao processar webhook: %v", err)
+		return
+	}
+
+	if len(data.Response.Transaction.StatusText) == 0 {
+		t.Errorf("Status esperado, encontrado vazio")
+		return
+	}
+
+	t.Log(fmt.Sprintf("status = %v", data.Response.Transaction.PicPayStatus))
+}
@@ -0,0 +1,362 @@
+package gopayments
+
+import (
+	"context"
+	"errors"
+	"github.com/mobilemindtec/go-payments/api"
+	"github.com/mobilemindtec/go-payments/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestWebhookDedupMemory
+func TestWebhookDedupMemory(t *testing.T) {
+
+	dedup := webhook.NewDeduplicator(webhook.NewMemoryDedupStore())
+
+	count := 0
+	credit := func() error {
+		count++
+		return nil
+	}
+
+	_, err := dedup.Process("asaas:PAYMENT_RECEIVED:pay_123:RECEIVED", func() error {
+		return errors.New("database down")
+	})
+
+	if err == nil {
+		t.Errorf("Erro do callback esperado")
+		return
+	}
+
+	// falhou, deve processar novamente
+	if processed, err := dedup.Process("asaas:PAYMENT_RECEIVED:pay_123:RECEIVED", credit); !processed || err != nil {
+		t.Errorf("Evento deveria ser processado: %v", err)
+		return
+	}
+
+	// reenvio do provedor
+	if processed, err := dedup.Process("asaas:PAYMENT_RECEIVED:pay_123:RECEIVED", credit); processed || err != nil {
+		t.Errorf("Evento duplicado não deveria ser processado: %v", err)
+		return
+	}
+
+	if count != 1 {
+		t.Errorf("Evento processado %v vezes", count)
+	}
+}
+
+type flakyDedupStore struct {
+	*webhook.MemoryDedupStore
+	completeErrors int
+}
+
+func (this *flakyDedupStore) Complete(key string, owner string, ttl time.Duration) error {
+	if this.completeErrors > 0 {
+		this.completeErrors--
+		return errors.New("database down")
+	}
+	return this.MemoryDedupStore.Complete(key, owner, ttl)
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestWebhookDedupCompleteError
+func TestWebhookDedupCompleteError(t *testing.T) {
+
+	store := &flakyDedupStore{MemoryDedupStore: webhook.NewMemoryDedupStore(), completeErrors: 2}
+	dedup := webhook.NewDeduplicator(store)
+
+	var completeErr error
+	dedup.OnCompleteError = func(key string, err error) {
+		completeErr = err
+	}
+
+	// Complete é repetido até funcionar
+	if processed, err := dedup.Process("asaas:pay_1", func() error { return nil }); !processed || err != nil || completeErr != nil {
+		t.Errorf("Evento deveria ser processado: %v, %v", err, completeErr)
+		return
+	}
+
+	if processed, _ := dedup.Process("asaas:pay_1", func() error { return nil }); processed {
+		t.Errorf("Evento deveria estar concluído")
+		return
+	}
+
+	// fn executou, a falha do Complete não é retornada
+	store.completeErrors = dedup.CompleteAttempts
+
+	if processed, err := dedup.Process("asaas:pay_2", func() error { return nil }); !processed || err != nil {
+		t.Errorf("Evento processado sem erro esperado: %v", err)
+		return
+	}
+
+	if completeErr == nil {
+		t.Errorf("OnCompleteError esperado")
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestWebhookDedupLease
+func TestWebhookDedupLease(t *testing.T) {
+
+	store := webhook.NewMemoryDedupStore()
+	dedup := webhook.NewDeduplicator(store)
+	dedup.Lease = 20 * time.Millisecond
+
+	// worker que travou no meio do processamento
+	if result, _ := store.Claim("pagarme:hook_123", "crashed", dedup.Lease); result != webhook.ClaimAcquired {
+		t.Errorf("Claim esperado, encontrado %v", result)
+		return
+	}
+
+	if _, err := dedup.Process("pagarme:hook_123", func() error { return nil }); !errors.Is(err, webhook.ErrEventInProgress) {
+		t.Errorf("Evento em processamento esperado, encontrado %v", err)
+		return
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if processed, err := dedup.Process("pagarme:hook_123", func() error { return nil }); !processed || err != nil {
+		t.Errorf("Evento deveria ser processado após expirar o lease: %v", err)
+		return
+	}
+
+	if err := store.Complete("pagarme:hook_123", "crashed", time.Hour); !errors.Is(err, webhook.ErrLeaseLost) {
+		t.Errorf("Lease perdido esperado, encontrado %v", err)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestWebhookRelay
+func TestWebhookRelay(t *testing.T) {
+
+	failures := 2
+	failureStatus := http.StatusServiceUnavailable
+	received := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		err := webhook.VerifyRelaySignature(
+			"secret",
+			r.Header.Get(webhook.RelayTimestampHeader),
+			r.Header.Get(webhook.RelaySignatureHeader),
+			body, 0)
+
+		if err != nil {
+			t.Errorf("Assinatura inválida: %v", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if failures > 0 {
+			failures--
+			w.WriteHeader(failureStatus)
+			return
+		}
+
+		received++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	deadLetter := webhook.NewMemoryDeadLetterQueue()
+	relay := webhook.NewRelay("secret", deadLetter)
+	relay.InitialBackoff = time.Millisecond
+	relay.MaxAttempts = 3
+
+	event := webhook.NewRelayEvent(api.PaymentEventReceived, api.GatewayAsaas)
+	event.OrderId = "123"
+	event.Status = api.PaymentPaid
+
+	if err := relay.Deliver(context.Background(), server.URL, event); err != nil || received != 1 {
+		t.Errorf("Entrega esperada: %v", err)
+		return
+	}
+
+	// falha em todas as tentativas, vai para dead letter
+	failures = 3
+
+	if err := relay.Deliver(context.Background(), server.URL, event); err == nil {
+		t.Errorf("Erro de entrega esperado")
+		return
+	}
+
+	deliveries, _ := deadLetter.List()
+
+	if len(deliveries) != 1 || deliveries[0].Attempts != 3 || deliveries[0].LastStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Entrega na dead letter esperada: %v", deliveries)
+		return
+	}
+
+	// a dead letter retorna cópias
+	deliveries[0].Attempts = 0
+
+	if delivery, _ := deadLetter.Get(deliveries[0].Id); delivery.Attempts != 3 {
+		t.Errorf("Entrega da dead letter não deveria ser alterada: %v", delivery.Attempts)
+		return
+	}
+
+	// replay com falha mantém a entrega na dead letter, sem novas tentativas
+	failures = 2
+
+	if err := relay.Replay(context.Background(), deliveries[0].Id); err == nil || failures != 1 {
+		t.Errorf("Erro de replay com uma tentativa esperado: %v", err)
+		return
+	}
+
+	if deliveries, _ = deadLetter.List(); len(deliveries) != 1 || deliveries[0].Attempts != 4 {
+		t.Errorf("Entrega deveria continuar na dead letter: %v", deliveries)
+		return
+	}
+
+	failures = 0
+
+	if err := relay.Replay(context.Background(), deliveries[0].Id); err != nil || received != 2 {
+		t.Errorf("Replay esperado: %v", err)
+		return
+	}
+
+	if deliveries, _ = deadLetter.List(); len(deliveries) != 0 {
+		t.Errorf("Dead letter deveria estar vazia")
+		return
+	}
+
+	// erro 4xx não é reenviado
+	failures = 3
+	failureStatus = http.StatusBadRequest
+
+	if err := relay.Deliver(context.Background(), server.URL, event); err == nil {
+		t.Errorf("Erro de entrega esperado")
+		return
+	}
+
+	if deliveries, _ = deadLetter.List(); len(deliveries) != 1 || deliveries[0].Attempts != 1 || failures != 2 {
+		t.Errorf("Entrega 4xx na dead letter sem retry esperada: %v", deliveries)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestWebhookInbox
+func TestWebhookInbox(t *testing.T) {
+
+	inbox, err := webhook.NewDefaultInbox(t.TempDir())
+
+	if err != nil {
+		t.Errorf("Erro ao criar inbox: %v", err)
+		return
+	}
+
+	token := "token"
+	inbox.WithReplayCredentials(api.GatewayAsaas, webhook.HeaderCredentials("asaas-access-token", func() string {
+		return token
+	}))
+
+	down := true
+	handler := inbox.Handler(api.GatewayAsaas, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if down || r.Header.Get("asaas-access-token") != "token" || !strings.Contains(string(body), "pay_123") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook/asaas", strings.NewReader(`{"event": "PAYMENT_RECEIVED", "payment": {"id": "pay_123"}}`))
+	request.Header.Set("asaas-access-token", "token")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Status 500 esperado, encontrado %v", recorder.Code)
+		return
+	}
+
+	events, _ := inbox.Store.List(webhook.InboxFilter{Status: webhook.InboxFailed})
+
+	if len(events) != 1 {
+		t.Errorf("Evento com falha esperado na inbox")
+		return
+	}
+
+	if len(events[0].Headers.Get("asaas-access-token")) > 0 {
+		t.Errorf("Token não deveria ser salvo na inbox")
+		return
+	}
+
+	down = false
+
+	errs, err := inbox.ReplayRange(webhook.InboxFilter{
+		Gateway: api.GatewayAsaas,
+		From:    time.Now().Add(-time.Minute),
+		To:      time.Now(),
+	})
+
+	if err != nil || len(errs) > 0 {
+		t.Errorf("Erro no replay: %v %v", err, errs)
+		return
+	}
+
+	event, _ := inbox.Store.Get(events[0].Id)
+
+	if event.Status != webhook.InboxProcessed || event.Attempts != 2 {
+		t.Errorf("Evento processado esperado, encontrado %v tentativas %v", event.Status, event.Attempts)
+		return
+	}
+
+	// eventos processados não são reenviados sem filtro de status
+	if _, err := inbox.ReplayRange(webhook.InboxFilter{Gateway: api.GatewayAsaas}); err != nil {
+		t.Errorf("Erro no replay: %v", err)
+		return
+	}
+
+	event, _ = inbox.Store.Get(events[0].Id)
+
+	if event.Attempts != 2 {
+		t.Errorf("Evento processado não deveria ser reenviado, tentativas %v", event.Attempts)
+	}
+}
+
+// go test -v  github.com/mobilemindtec/go-payments/tests -run TestWebhookInboxStaleProcessing
+func TestWebhookInboxStaleProcessing(t *testing.T) {
+
+	inbox, err := webhook.NewDefaultInbox(t.TempDir())
+
+	if err != nil {
+		t.Errorf("Erro ao criar inbox: %v", err)
+		return
+	}
+
+	inbox.ProcessingTimeout = time.Minute
+	inbox.Handler(api.GatewayAsaas, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	now := time.Now()
+
+	// interrompido por crash, nunca foi concluído
+	stale := &webhook.InboxEvent{Id: "1-stale", Gateway: api.GatewayAsaas, Method: http.MethodPost, Url: "/webhook/asaas",
+		Status: webhook.InboxProcessing, Attempts: 1, ReceivedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour)}
+	// ainda em processamento
+	running := &webhook.InboxEvent{Id: "2-running", Gateway: api.GatewayAsaas, Method: http.MethodPost, Url: "/webhook/asaas",
+		Status: webhook.InboxProcessing, Attempts: 1, ReceivedAt: now, UpdatedAt: now}
+
+	inbox.Store.Save(stale)
+	inbox.Store.Save(running)
+
+	errs, err := inbox.ReplayRange(webhook.InboxFilter{Gateway: api.GatewayAsaas})
+
+	if err != nil || len(errs) > 0 {
+		t.Errorf("Erro no replay: %v %v", err, errs)
+		return
+	}
+
+	if event, _ := inbox.Store.Get(stale.Id); event.Status != webhook.InboxProcessed {
+		t.Errorf("Evento interrompido deveria ser processado, encontrado %v", event.Status)
+		return
+	}
+
+	if event, _ := inbox.Store.Get(running.Id); event.Status != webhook.InboxProcessing || event.Attempts != 1 {
+		t.Errorf("Evento em processamento não deveria ser reenviado, encontrado %v", event.Status)
+	}
+}
@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	uuid "github.com/satori/go.uuid"
+	"net/http"
+	"time"
+)
+
+type ClaimResult int
+
+const (
+	// the event was claimed and must be processed
+	ClaimAcquired ClaimResult = iota + 1
+	// other worker is processing the event and the lease has not expired
+	ClaimInProgress
+	// the event was already processed
+	ClaimDone
+)
+
+const (
+	DefaultDedupLease = 5 * time.Minute
+	DefaultDedupTTL   = 30 * 24 * time.Hour
+	// attempts to mark the event as done after fn succeeds
+	DefaultDedupCompleteAttempts = 3
+)
+
+var (
+	ErrEventInProgress = errors.New("webhook event in progress")
+	ErrLeaseLost       = errors.New("webhook event lease lost")
+)
+
+// DedupStore keep the processing state of webhook events.
+//
+// Claim take a lease to process the event, if the worker crashes the lease
+// expires and the event can be claimed again. Complete mark the event as done
+// and keep it for ttl, Release drop the lease so the event can be retried now.
+// Complete and Release only act when owner still holds the lease.
+type DedupStore interface {
+	Claim(key string, owner string, lease time.Duration) (ClaimResult, error)
+	Complete(key string, owner string, ttl time.Duration) error
+	Release(key string, owner string) error
+}
+
+// DedupKeyer is implemented by the gateways WebhookData
+type DedupKeyer interface {
+	DedupKey() string
+}
+
+type Deduplicator struct {
+	Store DedupStore
+	// max time to process an event, after that other worker can claim it
+	Lease time.Duration
+	// time to remember processed events, must be greater than the provider retry window
+	TTL time.Duration
+	// attempts of Store.Complete, ErrLeaseLost is not retried
+	CompleteAttempts int
+
+	// called when the event was processed but can't be marked as done. The event
+	// can be processed again after the lease expires
+	OnCompleteError func(key string, err error)
+}
+
+func NewDeduplicator(store DedupStore) *Deduplicator {
+	return &Deduplicator{
+		Store:            store,
+		Lease:            DefaultDedupLease,
+		TTL:              DefaultDedupTTL,
+		CompleteAttempts: DefaultDedupCompleteAttempts,
+	}
+}
+
+// Process run fn once per key. Returns true when fn was executed with success.
+//
+// Already processed events are skipped and return false without error, so the
+// webhook can be acknowledged. Events in progress return ErrEventInProgress
+// with status 409, so the provider deliver again later. When fn fails the
+// claim is released and the event stays retryable. When fn succeeds but the
+// event can't be marked as done, OnCompleteError is called and Process returns
+// true without error, fn side effects already happened.
+func (this *Deduplicator) Process(key string, fn func() error) (bool, error) {
+
+	if len(key) == 0 {
+		return false, errors.New("webhook dedup key is required")
+	}
+
+	owner := uuid.NewV4().String()
+
+	result, err := this.Store.Claim(key, owner, this.Lease)
+
+	if err != nil {
+		return false, fmt.Errorf("error on claim webhook event %v: %v", key, err)
+	}
+
+	switch result {
+	case ClaimDone:
+		return false, nil
+	case ClaimInProgress:
+		return false, api.NewWebhookHandlerError(http.StatusConflict, ErrEventInProgress)
+	}
+
+	if err := fn(); err != nil {
+		if releaseErr := this.Store.Release(key, owner); releaseErr != nil {
+			return false, fmt.Errorf("%v (release error: %v)", err, releaseErr)
+		}
+		return false, err
+	}
+
+	if err := this.complete(key, owner); err != nil {
+		err = fmt.Errorf("error on complete webhook event %v: %v", key, err)
+		if this.OnCompleteError != nil {
+			this.OnCompleteError(key, err)
+		} else {
+			fmt.Println("**** Webhook.Deduplicator: ", err)
+		}
+	}
+
+	return true, nil
+}
+
+func (this *Deduplicator) complete(key string, owner string) error {
+
+	var err error
+
+	for attempt := 0; attempt < max(this.CompleteAttempts, 1); attempt++ {
+		if err = this.Store.Complete(key, owner, this.TTL); err == nil || errors.Is(err, ErrLeaseLost) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// ProcessData run fn once per webhook data
+func (this *Deduplicator) ProcessData(data DedupKeyer, fn func() error) (bool, error) {
+	return this.Process(data.DedupKey(), fn)
+}
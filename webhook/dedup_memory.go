@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+type dedupStatus int
+
+const (
+	dedupProcessing dedupStatus = iota + 1
+	dedupDone
+)
+
+type memoryEntry struct {
+	owner     string
+	status    dedupStatus
+	expiresAt time.Time
+}
+
+// MemoryDedupStore keep events in memory, only to single instance services
+type MemoryDedupStore struct {
+	mutex   sync.Mutex
+	entries map[string]*memoryEntry
+	// clock, used by tests
+	now func() time.Time
+}
+
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{entries: map[string]*memoryEntry{}, now: time.Now}
+}
+
+func (this *MemoryDedupStore) Claim(key string, owner string, lease time.Duration) (ClaimResult, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	now := this.now()
+
+	if entry, ok := this.entries[key]; ok && entry.expiresAt.After(now) {
+		if entry.status == dedupDone {
+			return ClaimDone, nil
+		}
+		return ClaimInProgress, nil
+	}
+
+	this.entries[key] = &memoryEntry{owner: owner, status: dedupProcessing, expiresAt: now.Add(lease)}
+	return ClaimAcquired, nil
+}
+
+func (this *MemoryDedupStore) Complete(key string, owner string, ttl time.Duration) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	entry, ok := this.entries[key]
+
+	if !ok || entry.owner != owner || entry.status != dedupProcessing {
+		return ErrLeaseLost
+	}
+
+	entry.status = dedupDone
+	entry.expiresAt = this.now().Add(ttl)
+	return nil
+}
+
+func (this *MemoryDedupStore) Release(key string, owner string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if entry, ok := this.entries[key]; ok && entry.owner == owner && entry.status == dedupProcessing {
+		delete(this.entries, key)
+	}
+	return nil
+}
+
+// Cleanup remove expired entries
+func (this *MemoryDedupStore) Cleanup() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	now := this.now()
+	for key, entry := range this.entries {
+		if !entry.expiresAt.After(now) {
+			delete(this.entries, key)
+		}
+	}
+}
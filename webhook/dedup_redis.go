@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"fmt"
+	"github.com/go-redis/redis"
+	"time"
+)
+
+const (
+	DefaultRedisDedupPrefix = "webhook:dedup:"
+
+	redisDedupDone = "done"
+)
+
+// compare owner and set done
+var redisCompleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3]) and 1
+end
+return 0`)
+
+// compare owner and delete
+var redisReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisDedupStore keep events on redis, the lease and ttl use the key expiration
+type RedisDedupStore struct {
+	Client redis.Cmdable
+	Prefix string
+}
+
+func NewRedisDedupStore(client redis.Cmdable) *RedisDedupStore {
+	return &RedisDedupStore{Client: client, Prefix: DefaultRedisDedupPrefix}
+}
+
+func (this *RedisDedupStore) Claim(key string, owner string, lease time.Duration) (ClaimResult, error) {
+
+	redisKey := this.Prefix + key
+
+	ok, err := this.Client.SetNX(redisKey, this.processingValue(owner), lease).Result()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return ClaimAcquired, nil
+	}
+
+	value, err := this.Client.Get(redisKey).Result()
+
+	if err == redis.Nil {
+		// expired between SetNX and Get
+		return this.Claim(key, owner, lease)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if value == redisDedupDone {
+		return ClaimDone, nil
+	}
+
+	return ClaimInProgress, nil
+}
+
+func (this *RedisDedupStore) Complete(key string, owner string, ttl time.Duration) error {
+
+	result, err := redisCompleteScript.Run(
+		this.Client,
+		[]string{this.Prefix + key},
+		this.processingValue(owner), redisDedupDone, ttl.Milliseconds()).Int64()
+
+	if err != nil {
+		return err
+	}
+
+	if result == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (this *RedisDedupStore) Release(key string, owner string) error {
+	return redisReleaseScript.Run(
+		this.Client,
+		[]string{this.Prefix + key},
+		this.processingValue(owner)).Err()
+}
+
+func (this *RedisDedupStore) processingValue(owner string) string {
+	return fmt.Sprintf("processing:%v", owner)
+}
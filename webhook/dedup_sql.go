@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSQLDedupTable = "webhook_dedup"
+
+	sqlStatusProcessing = "processing"
+	sqlStatusDone       = "done"
+)
+
+// SQLDialect adapt the statements to the database driver
+type SQLDialect struct {
+	// insert that ignore duplicated primary key, %v is the table name
+	InsertIgnore string
+	// placeholder to argument n, starting at 1
+	Placeholder func(n int) string
+}
+
+var (
+	SQLDialectPostgres = &SQLDialect{
+		InsertIgnore: "INSERT INTO %v (event_key, owner, status, expires_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+		Placeholder:  func(n int) string { return fmt.Sprintf("$%v", n) },
+	}
+	SQLDialectMySQL = &SQLDialect{
+		InsertIgnore: "INSERT IGNORE INTO %v (event_key, owner, status, expires_at) VALUES (?, ?, ?, ?)",
+		Placeholder:  func(n int) string { return "?" },
+	}
+	SQLDialectSQLite = &SQLDialect{
+		InsertIgnore: "INSERT OR IGNORE INTO %v (event_key, owner, status, expires_at) VALUES (?, ?, ?, ?)",
+		Placeholder:  func(n int) string { return "?" },
+	}
+)
+
+// SQLDedupStore keep events on a SQL table. Use CreateTable or create the table:
+//
+//	CREATE TABLE webhook_dedup (
+//		event_key VARCHAR(255) PRIMARY KEY,
+//		owner VARCHAR(64) NOT NULL,
+//		status VARCHAR(16) NOT NULL,
+//		expires_at BIGINT NOT NULL
+//	)
+//
+// expires_at is unix time in seconds.
+type SQLDedupStore struct {
+	DB      *sql.DB
+	Table   string
+	Dialect *SQLDialect
+	// clock, used by tests
+	now func() time.Time
+}
+
+func NewSQLDedupStore(db *sql.DB, dialect *SQLDialect) *SQLDedupStore {
+	return &SQLDedupStore{DB: db, Table: DefaultSQLDedupTable, Dialect: dialect, now: time.Now}
+}
+
+func (this *SQLDedupStore) CreateTable() error {
+	_, err := this.DB.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		event_key VARCHAR(255) PRIMARY KEY,
+		owner VARCHAR(64) NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		expires_at BIGINT NOT NULL)`, this.Table))
+	return err
+}
+
+func (this *SQLDedupStore) Claim(key string, owner string, lease time.Duration) (ClaimResult, error) {
+	return this.claim(context.Background(), this.DB, key, owner, sqlStatusProcessing, lease)
+}
+
+func (this *SQLDedupStore) Complete(key string, owner string, ttl time.Duration) error {
+
+	affected, err := this.exec(context.Background(), this.DB,
+		"UPDATE %v SET status = {1}, expires_at = {2} WHERE event_key = {3} AND owner = {4} AND status = {5}",
+		sqlStatusDone, this.now().Add(ttl).Unix(), key, owner, sqlStatusProcessing)
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (this *SQLDedupStore) Release(key string, owner string) error {
+	_, err := this.exec(context.Background(), this.DB,
+		"DELETE FROM %v WHERE event_key = {1} AND owner = {2} AND status = {3}",
+		key, owner, sqlStatusProcessing)
+	return err
+}
+
+// Cleanup remove expired processed events
+func (this *SQLDedupStore) Cleanup() error {
+	_, err := this.exec(context.Background(), this.DB,
+		"DELETE FROM %v WHERE status = {1} AND expires_at < {2}",
+		sqlStatusDone, this.now().Unix())
+	return err
+}
+
+// ProcessTx run fn and mark the event as done on the same transaction, so the
+// event is never half applied: if fn fails or the process crashes nothing is
+// committed and the event stays retryable. Concurrent deliveries wait the
+// primary key lock and are skipped after the commit. Returns true when fn was
+// executed and committed. Events in progress return ErrEventInProgress with
+// status 409, as Deduplicator.Process.
+func (this *SQLDedupStore) ProcessTx(ctx context.Context, key string, ttl time.Duration, fn func(tx *sql.Tx) error) (bool, error) {
+
+	tx, err := this.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	result, err := this.claim(ctx, tx, key, "tx", sqlStatusDone, ttl)
+
+	if err != nil || result != ClaimAcquired {
+		tx.Rollback()
+		if result == ClaimInProgress {
+			return false, api.NewWebhookHandlerError(http.StatusConflict, ErrEventInProgress)
+		}
+		return false, err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (this *SQLDedupStore) claim(ctx context.Context, db sqlExecutor, key string, owner string, status string, duration time.Duration) (ClaimResult, error) {
+
+	now := this.now()
+	expiresAt := now.Add(duration).Unix()
+
+	result, err := db.ExecContext(ctx, fmt.Sprintf(this.Dialect.InsertIgnore, this.Table), key, owner, status, expiresAt)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return 0, err
+	} else if affected == 1 {
+		return ClaimAcquired, nil
+	}
+
+	// take the expired lease of a crashed worker
+	affected, err := this.exec(ctx, db,
+		"UPDATE %v SET owner = {1}, status = {2}, expires_at = {3} WHERE event_key = {4} AND status = {5} AND expires_at < {6}",
+		owner, status, expiresAt, key, sqlStatusProcessing, now.Unix())
+
+	if err != nil {
+		return 0, err
+	}
+
+	if affected == 1 {
+		return ClaimAcquired, nil
+	}
+
+	var currentStatus string
+	var currentExpiresAt int64
+
+	err = db.QueryRowContext(ctx,
+		this.query("SELECT status, expires_at FROM %v WHERE event_key = {1}"), key).
+		Scan(&currentStatus, &currentExpiresAt)
+
+	if err == sql.ErrNoRows {
+		// released between insert and select
+		return this.claim(ctx, db, key, owner, status, duration)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if currentStatus == sqlStatusDone {
+		if currentExpiresAt < now.Unix() {
+			// done but expired, process again
+			if _, err := this.exec(ctx, db, "DELETE FROM %v WHERE event_key = {1} AND expires_at < {2}", key, now.Unix()); err != nil {
+				return 0, err
+			}
+			return this.claim(ctx, db, key, owner, status, duration)
+		}
+		return ClaimDone, nil
+	}
+
+	return ClaimInProgress, nil
+}
+
+func (this *SQLDedupStore) exec(ctx context.Context, db sqlExecutor, query string, args ...interface{}) (int64, error) {
+
+	result, err := db.ExecContext(ctx, this.query(query), args...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// query set table name and replace {n} by dialect placeholder
+func (this *SQLDedupStore) query(query string) string {
+	return sqlQuery(this.Table, this.Dialect, query)
+}
+
+func sqlQuery(table string, dialect *SQLDialect, query string) string {
+	query = fmt.Sprintf(query, table)
+	for n := 1; strings.Contains(query, fmt.Sprintf("{%v}", n)); n++ {
+		query = strings.Replace(query, fmt.Sprintf("{%v}", n), dialect.Placeholder(n), 1)
+	}
+	return query
+}
@@ -0,0 +1,386 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	uuid "github.com/satori/go.uuid"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type InboxStatus string
+
+const (
+	InboxReceived   InboxStatus = "received"
+	InboxProcessing InboxStatus = "processing"
+	InboxProcessed  InboxStatus = "processed"
+	InboxFailed     InboxStatus = "failed"
+)
+
+var ErrInboxEventNotFound = errors.New("webhook inbox event not found")
+
+// DefaultInboxProcessingTimeout processing events not updated after this time were
+// interrupted, eg. by a crash or deploy, and are replayed again
+const DefaultInboxProcessingTimeout = 5 * time.Minute
+
+// DefaultInboxSecretHeaders are removed from the event before store, the
+// credentials are added again on replay by InboxCredentials
+var DefaultInboxSecretHeaders = []string{"Authorization", "asaas-access-token", "x-seller-token"}
+
+// InboxCredentials add the current gateway credentials to the headers of a
+// replayed event, eg. the asaas-access-token of the live webhook config
+type InboxCredentials func(event *InboxEvent, header http.Header) error
+
+// HeaderCredentials set header name with the value returned by value on each replay
+func HeaderCredentials(name string, value func() string) InboxCredentials {
+	return func(event *InboxEvent, header http.Header) error {
+		header.Set(name, value())
+		return nil
+	}
+}
+
+// InboxEvent is a raw webhook request as received from gateway. Secret headers
+// are not stored, see Inbox.SecretHeaders.
+type InboxEvent struct {
+	Id          string      `json:"id"`
+	Gateway     api.Gateway `json:"gateway"`
+	Method      string      `json:"method"`
+	Url         string      `json:"url"`
+	RemoteAddr  string      `json:"remote_addr"`
+	Headers     http.Header `json:"headers"`
+	Body        []byte      `json:"body"`
+	Status      InboxStatus `json:"status"`
+	Attempts    int         `json:"attempts"`
+	StatusCode  int         `json:"status_code"`
+	LastError   string      `json:"last_error"`
+	ReceivedAt  time.Time   `json:"received_at"`
+	ProcessedAt time.Time   `json:"processed_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// IsStale event in processing not updated after timeout
+func (this *InboxEvent) IsStale(timeout time.Duration) bool {
+	updatedAt := this.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = this.ReceivedAt
+	}
+	return this.Status == InboxProcessing && time.Since(updatedAt) > timeout
+}
+
+// InboxFilter select events to list and replay, zero values are ignored
+type InboxFilter struct {
+	Gateway api.Gateway
+	Status  InboxStatus
+	From    time.Time
+	To      time.Time
+}
+
+func (this InboxFilter) Match(event *InboxEvent) bool {
+	if len(this.Gateway) > 0 && event.Gateway != this.Gateway {
+		return false
+	}
+	if len(this.Status) > 0 && event.Status != this.Status {
+		return false
+	}
+	if !this.From.IsZero() && event.ReceivedAt.Before(this.From) {
+		return false
+	}
+	if !this.To.IsZero() && event.ReceivedAt.After(this.To) {
+		return false
+	}
+	return true
+}
+
+type InboxStore interface {
+	// Save insert or update the event
+	Save(event *InboxEvent) error
+	Get(id string) (*InboxEvent, error)
+	// List returns events ordered by ReceivedAt
+	List(filter InboxFilter) ([]*InboxEvent, error)
+}
+
+// Inbox store every webhook request before processing, so events are never lost
+// and can be replayed through the current handlers.
+type Inbox struct {
+	Store       InboxStore
+	MaxBodySize int64
+	// reply 200 right after store the event and process in background. Use when
+	// the gateway penalizes slow or failed deliveries, failed events stay on inbox to replay
+	AckOnStore bool
+	// headers removed before store the event
+	SecretHeaders []string
+	// processing events older than timeout are replayed by ReplayRange
+	ProcessingTimeout time.Duration
+	Debug             bool
+
+	mutex       sync.RWMutex
+	handlers    map[api.Gateway]http.Handler
+	credentials map[api.Gateway]InboxCredentials
+}
+
+func NewInbox(store InboxStore) *Inbox {
+	return &Inbox{
+		Store:             store,
+		MaxBodySize:       api.DefaultWebhookMaxBodySize,
+		SecretHeaders:     DefaultInboxSecretHeaders,
+		ProcessingTimeout: DefaultInboxProcessingTimeout,
+		handlers:          map[api.Gateway]http.Handler{},
+		credentials:       map[api.Gateway]InboxCredentials{},
+	}
+}
+
+// NewDefaultInbox create a inbox with file store on dir
+func NewDefaultInbox(dir string) (*Inbox, error) {
+	store, err := NewFileInboxStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewInbox(store), nil
+}
+
+// WithReplayCredentials register the credentials added to replayed events of gateway, eg.
+// inbox.WithReplayCredentials(api.GatewayAsaas, webhook.HeaderCredentials(asaas.WebhookAccessTokenHeader, getToken))
+func (this *Inbox) WithReplayCredentials(gateway api.Gateway, credentials InboxCredentials) *Inbox {
+	this.mutex.Lock()
+	this.credentials[gateway] = credentials
+	this.mutex.Unlock()
+	return this
+}
+
+// Handler store the request on inbox and call the gateway handler, eg.
+// inbox.Handler(api.GatewayAsaas, asaas.NewWebhookHandler(...)). The handler is
+// registered to replay events of gateway.
+func (this *Inbox) Handler(gateway api.Gateway, next http.Handler) http.Handler {
+
+	this.mutex.Lock()
+	this.handlers[gateway] = next
+	this.mutex.Unlock()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		body, err := api.ReadWebhookBody(w, r, this.MaxBodySize)
+
+		if err != nil {
+			api.WriteWebhookError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		// the live request keeps the secret headers, only the stored event is redacted
+		header := r.Header.Clone()
+
+		now := time.Now()
+
+		event := &InboxEvent{
+			Id:         fmt.Sprintf("%020d-%v", now.UnixNano(), uuid.NewV4().String()[:8]),
+			Gateway:    gateway,
+			Method:     r.Method,
+			Url:        r.URL.String(),
+			RemoteAddr: r.RemoteAddr,
+			Headers:    this.redact(r.Header),
+			Body:       body,
+			Status:     InboxReceived,
+			ReceivedAt: now,
+			UpdatedAt:  now,
+		}
+
+		if err := this.Store.Save(event); err != nil {
+			if this.Debug {
+				fmt.Println("**** Webhook.Inbox: error on save event: ", err)
+			}
+			// not stored, gateway must deliver again
+			api.WriteWebhookError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if this.AckOnStore {
+			api.WriteWebhookAck(w)
+			go this.process(next, event, header, nil)
+			return
+		}
+
+		this.process(next, event, header, w)
+	})
+}
+
+// Replay run one event again through the current gateway handler
+func (this *Inbox) Replay(id string) (*InboxEvent, error) {
+
+	event, err := this.Store.Get(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	this.mutex.RLock()
+	handler, ok := this.handlers[event.Gateway]
+	credentials := this.credentials[event.Gateway]
+	this.mutex.RUnlock()
+
+	if !ok {
+		return event, fmt.Errorf("no webhook handler to gateway %v", event.Gateway)
+	}
+
+	header := event.Headers.Clone()
+
+	if header == nil {
+		header = http.Header{}
+	}
+
+	if credentials != nil {
+		if err := credentials(event, header); err != nil {
+			return event, err
+		}
+	}
+
+	if err := this.process(handler, event, header, nil); err != nil {
+		return event, err
+	}
+
+	return event, nil
+}
+
+// ReplayRange replay the events selected by filter, eg. failed events of a time range.
+// Without filter status received, failed and stale processing events are replayed,
+// processed events are replayed only when filter.Status is InboxProcessed. Processing
+// events are replayed only after ProcessingTimeout. Returns the errors by event id
+func (this *Inbox) ReplayRange(filter InboxFilter) (map[string]error, error) {
+
+	statuses := []InboxStatus{filter.Status}
+
+	if len(filter.Status) == 0 {
+		statuses = []InboxStatus{InboxReceived, InboxFailed, InboxProcessing}
+	}
+
+	timeout := this.ProcessingTimeout
+	if timeout <= 0 {
+		timeout = DefaultInboxProcessingTimeout
+	}
+
+	events := []*InboxEvent{}
+
+	for _, status := range statuses {
+		filter.Status = status
+		found, err := this.Store.List(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range found {
+			// still running on handler or background
+			if event.Status == InboxProcessing && !event.IsStale(timeout) {
+				continue
+			}
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ReceivedAt.Before(events[j].ReceivedAt)
+	})
+
+	errs := map[string]error{}
+
+	for _, event := range events {
+		if _, err := this.Replay(event.Id); err != nil {
+			errs[event.Id] = err
+		}
+	}
+
+	return errs, nil
+}
+
+// process run the handler with header and save the result. When w is nil the response is discarded
+func (this *Inbox) process(handler http.Handler, event *InboxEvent, header http.Header, w http.ResponseWriter) error {
+
+	event.Status = InboxProcessing
+	event.Attempts++
+	this.save(event)
+
+	req, err := http.NewRequest(event.Method, event.Url, bytes.NewReader(event.Body))
+
+	if err != nil {
+		event.Status = InboxFailed
+		event.LastError = err.Error()
+		this.save(event)
+		return err
+	}
+
+	req.Header = header
+	req.RemoteAddr = event.RemoteAddr
+
+	recorder := newInboxResponseWriter(w)
+	handler.ServeHTTP(recorder, req)
+
+	event.StatusCode = recorder.statusCode
+	event.ProcessedAt = time.Now()
+
+	if recorder.statusCode >= 200 && recorder.statusCode <= 299 {
+		event.Status = InboxProcessed
+		event.LastError = ""
+	} else {
+		event.Status = InboxFailed
+		event.LastError = fmt.Sprintf("handler replied status %v: %v", recorder.statusCode, recorder.body.String())
+		err = errors.New(event.LastError)
+	}
+
+	this.save(event)
+	return err
+}
+
+// redact copy the headers without the secret headers
+func (this *Inbox) redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range this.SecretHeaders {
+		header.Del(name)
+	}
+	return header
+}
+
+func (this *Inbox) save(event *InboxEvent) {
+	event.UpdatedAt = time.Now()
+	if err := this.Store.Save(event); err != nil && this.Debug {
+		fmt.Println("**** Webhook.Inbox: error on save event: ", err)
+	}
+}
+
+// inboxResponseWriter capture the status code and forward to w when not nil
+type inboxResponseWriter struct {
+	w          http.ResponseWriter
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newInboxResponseWriter(w http.ResponseWriter) *inboxResponseWriter {
+	recorder := &inboxResponseWriter{w: w, statusCode: http.StatusOK}
+	if w != nil {
+		recorder.header = w.Header()
+	} else {
+		recorder.header = http.Header{}
+	}
+	return recorder
+}
+
+func (this *inboxResponseWriter) Header() http.Header {
+	return this.header
+}
+
+func (this *inboxResponseWriter) WriteHeader(statusCode int) {
+	this.statusCode = statusCode
+	if this.w != nil {
+		this.w.WriteHeader(statusCode)
+	}
+}
+
+func (this *inboxResponseWriter) Write(data []byte) (int, error) {
+	if this.body.Len() < 1024 {
+		this.body.Write(data)
+	}
+	if this.w != nil {
+		return this.w.Write(data)
+	}
+	return len(data), nil
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileInboxStore keep each event as a json file on dir. Event ids are
+// prefixed with the receive time, so files are sorted by receive order.
+type FileInboxStore struct {
+	Dir   string
+	mutex sync.Mutex
+}
+
+func NewFileInboxStore(dir string) (*FileInboxStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileInboxStore{Dir: dir}, nil
+}
+
+func (this *FileInboxStore) Save(event *InboxEvent) error {
+
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	// write and rename, a crash never leaves a partial file
+	tmp := this.path(event.Id) + ".tmp"
+
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, this.path(event.Id))
+}
+
+func (this *FileInboxStore) Get(id string) (*InboxEvent, error) {
+
+	if strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid inbox event id %v", id)
+	}
+
+	data, err := os.ReadFile(this.path(id))
+
+	if os.IsNotExist(err) {
+		return nil, ErrInboxEventNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	event := new(InboxEvent)
+	return event, json.Unmarshal(data, event)
+}
+
+func (this *FileInboxStore) List(filter InboxFilter) ([]*InboxEvent, error) {
+
+	files, err := filepath.Glob(filepath.Join(this.Dir, "*.json"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	events := []*InboxEvent{}
+
+	for _, file := range files {
+
+		event, err := this.Get(strings.TrimSuffix(filepath.Base(file), ".json"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (this *FileInboxStore) path(id string) string {
+	return filepath.Join(this.Dir, id+".json")
+}
@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSQLInboxTable = "webhook_inbox"
+)
+
+// SQLInboxStore keep events on a SQL table, eg. SQLite with SQLDialectSQLite.
+// The database driver must be imported by the application. Times are unix nano.
+type SQLInboxStore struct {
+	DB      *sql.DB
+	Table   string
+	Dialect *SQLDialect
+}
+
+func NewSQLInboxStore(db *sql.DB, dialect *SQLDialect) *SQLInboxStore {
+	return &SQLInboxStore{DB: db, Table: DefaultSQLInboxTable, Dialect: dialect}
+}
+
+func (this *SQLInboxStore) CreateTable() error {
+	_, err := this.DB.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id VARCHAR(64) PRIMARY KEY,
+		gateway VARCHAR(32) NOT NULL,
+		method VARCHAR(16) NOT NULL,
+		url TEXT NOT NULL,
+		remote_addr VARCHAR(64) NOT NULL,
+		headers TEXT NOT NULL,
+		body TEXT NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		attempts INTEGER NOT NULL,
+		status_code INTEGER NOT NULL,
+		last_error TEXT NOT NULL,
+		received_at BIGINT NOT NULL,
+		processed_at BIGINT NOT NULL,
+		updated_at BIGINT NOT NULL)`, this.Table))
+	return err
+}
+
+func (this *SQLInboxStore) Save(event *InboxEvent) error {
+
+	headers, err := json.Marshal(event.Headers)
+
+	if err != nil {
+		return err
+	}
+
+	processedAt := int64(0)
+	if !event.ProcessedAt.IsZero() {
+		processedAt = event.ProcessedAt.UnixNano()
+	}
+
+	updatedAt := event.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = event.ReceivedAt
+	}
+
+	// RowsAffected can't be used to detect a new event, MySQL returns zero when
+	// the update don't change any value
+	var count int
+
+	err = this.DB.QueryRow(this.query("SELECT COUNT(*) FROM %v WHERE id = {1}"), event.Id).Scan(&count)
+
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		_, err = this.DB.Exec(
+			this.query("UPDATE %v SET status = {1}, attempts = {2}, status_code = {3}, last_error = {4}, processed_at = {5}, updated_at = {6} WHERE id = {7}"),
+			string(event.Status), event.Attempts, event.StatusCode, event.LastError, processedAt, updatedAt.UnixNano(), event.Id)
+		return err
+	}
+
+	_, err = this.DB.Exec(
+		this.query("INSERT INTO %v (id, gateway, method, url, remote_addr, headers, body, status, attempts, status_code, last_error, received_at, processed_at, updated_at) VALUES ({1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}, {10}, {11}, {12}, {13}, {14})"),
+		event.Id, string(event.Gateway), event.Method, event.Url, event.RemoteAddr, string(headers), string(event.Body),
+		string(event.Status), event.Attempts, event.StatusCode, event.LastError, event.ReceivedAt.UnixNano(), processedAt, updatedAt.UnixNano())
+
+	return err
+}
+
+func (this *SQLInboxStore) Get(id string) (*InboxEvent, error) {
+
+	rows, err := this.DB.Query(this.query(this.selectQuery()+" WHERE id = {1}"), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := this.scan(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, ErrInboxEventNotFound
+	}
+
+	return events[0], nil
+}
+
+func (this *SQLInboxStore) List(filter InboxFilter) ([]*InboxEvent, error) {
+
+	conditions := []string{}
+	args := []interface{}{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, fmt.Sprintf("{%v}", len(args))))
+	}
+
+	if len(filter.Gateway) > 0 {
+		add("gateway = %v", string(filter.Gateway))
+	}
+	if len(filter.Status) > 0 {
+		add("status = %v", string(filter.Status))
+	}
+	if !filter.From.IsZero() {
+		add("received_at >= %v", filter.From.UnixNano())
+	}
+	if !filter.To.IsZero() {
+		add("received_at <= %v", filter.To.UnixNano())
+	}
+
+	query := this.selectQuery()
+
+	if len(conditions) > 0 {
+		query = fmt.Sprintf("%v WHERE %v", query, strings.Join(conditions, " AND "))
+	}
+
+	rows, err := this.DB.Query(this.query(query+" ORDER BY received_at"), args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return this.scan(rows)
+}
+
+func (this *SQLInboxStore) selectQuery() string {
+	return "SELECT id, gateway, method, url, remote_addr, headers, body, status, attempts, status_code, last_error, received_at, processed_at, updated_at FROM %v"
+}
+
+func (this *SQLInboxStore) scan(rows *sql.Rows) ([]*InboxEvent, error) {
+
+	defer rows.Close()
+
+	events := []*InboxEvent{}
+
+	for rows.Next() {
+
+		var gateway, status, headers, body string
+		var receivedAt, processedAt, updatedAt int64
+		event := new(InboxEvent)
+
+		err := rows.Scan(
+			&event.Id, &gateway, &event.Method, &event.Url, &event.RemoteAddr, &headers, &body,
+			&status, &event.Attempts, &event.StatusCode, &event.LastError, &receivedAt, &processedAt, &updatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(headers), &event.Headers); err != nil {
+			return nil, err
+		}
+
+		event.Gateway = api.Gateway(gateway)
+		event.Status = InboxStatus(status)
+		event.Body = []byte(body)
+		event.ReceivedAt = time.Unix(0, receivedAt)
+		if processedAt > 0 {
+			event.ProcessedAt = time.Unix(0, processedAt)
+		}
+		event.UpdatedAt = time.Unix(0, updatedAt)
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// query set table name and replace {n} by dialect placeholder
+func (this *SQLInboxStore) query(query string) string {
+	return sqlQuery(this.Table, this.Dialect, query)
+}
@@ -0,0 +1,360 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mobilemindtec/go-payments/api"
+	uuid "github.com/satori/go.uuid"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	RelayEventIdHeader   = "X-Payments-Event-Id"
+	RelayTimestampHeader = "X-Payments-Timestamp"
+	RelaySignatureHeader = "X-Payments-Signature"
+
+	DefaultRelayMaxAttempts    = 8
+	DefaultRelayInitialBackoff = 5 * time.Second
+	DefaultRelayMaxBackoff     = 30 * time.Minute
+	DefaultRelayTimeout        = 30 * time.Second
+	DefaultRelayReplayAttempts = 1
+	// max age of timestamp accepted by VerifyRelaySignature
+	DefaultRelayTolerance = 5 * time.Minute
+)
+
+// RelayEvent is the normalized payment event sent to consumer api (WebhookUrl)
+type RelayEvent struct {
+	Id             string                 `json:"id"`
+	Event          api.PaymentEvent       `json:"event"`
+	Gateway        api.Gateway            `json:"gateway"`
+	OrderId        string                 `json:"order_id,omitempty"`
+	TransactionId  string                 `json:"transaction_id,omitempty"`
+	SubscriptionId string                 `json:"subscription_id,omitempty"`
+	Status         api.PaymentStatus      `json:"status"`
+	StatusLabel    api.PaymentStatusLabel `json:"status_label,omitempty"`
+	Amount         float64                `json:"amount,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Data           interface{}            `json:"data,omitempty"`
+}
+
+func NewRelayEvent(event api.PaymentEvent, gateway api.Gateway) *RelayEvent {
+	return &RelayEvent{
+		Id:        uuid.NewV4().String(),
+		Event:     event,
+		Gateway:   gateway,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewRelayEventFromResult create the event from a gateway payment result
+func NewRelayEventFromResult(event api.PaymentEvent, result *api.PaymentResult) *RelayEvent {
+	relayEvent := NewRelayEvent(event, result.Platform)
+	relayEvent.OrderId = result.OrderId
+	relayEvent.TransactionId = result.TransactionId
+	relayEvent.Status = result.Status
+	relayEvent.StatusLabel = result.StatusLabel
+	relayEvent.Amount = result.Amount
+	if result.SubscriptionInfo != nil {
+		relayEvent.SubscriptionId = result.SubscriptionInfo.SubscriptionId
+	}
+	return relayEvent
+}
+
+// RelayDelivery is a event delivery to one url
+type RelayDelivery struct {
+	Id             string      `json:"id"`
+	Url            string      `json:"url"`
+	Event          *RelayEvent `json:"event"`
+	Attempts       int         `json:"attempts"`
+	LastError      string      `json:"last_error"`
+	LastStatusCode int         `json:"last_status_code"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// copy the delivery and event, the queue don't share pointers with callers
+func (this *RelayDelivery) copy() *RelayDelivery {
+	delivery := *this
+	if this.Event != nil {
+		event := *this.Event
+		delivery.Event = &event
+	}
+	return &delivery
+}
+
+// DeadLetterQueue keep deliveries that failed after all attempts
+type DeadLetterQueue interface {
+	// Push insert or replace the delivery by id
+	Push(delivery *RelayDelivery) error
+	Get(id string) (*RelayDelivery, error)
+	List() ([]*RelayDelivery, error)
+	Remove(id string) error
+}
+
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+type Relay struct {
+	// secret to sign the payload with HMAC-SHA256
+	Secret         string
+	Client         *http.Client
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// attempts of each Replay, the delivery already failed on Deliver backoff
+	ReplayAttempts int
+	DeadLetter     DeadLetterQueue
+	Debug          bool
+
+	// called after each failed attempt
+	OnFailure func(delivery *RelayDelivery)
+}
+
+func NewRelay(secret string, deadLetter DeadLetterQueue) *Relay {
+	return &Relay{
+		Secret:         secret,
+		Client:         &http.Client{Timeout: DefaultRelayTimeout},
+		MaxAttempts:    DefaultRelayMaxAttempts,
+		InitialBackoff: DefaultRelayInitialBackoff,
+		MaxBackoff:     DefaultRelayMaxBackoff,
+		ReplayAttempts: DefaultRelayReplayAttempts,
+		DeadLetter:     deadLetter,
+	}
+}
+
+func (this *Relay) SetDebug() *Relay {
+	this.Debug = true
+	return this
+}
+
+// Deliver post the event to url, retrying with exponential backoff. When all attempts
+// fail the delivery is pushed to dead letter queue and the last error is returned.
+// Client errors (4xx) are not retried, except 408 and 429, the delivery goes
+// to dead letter queue on first attempt.
+func (this *Relay) Deliver(ctx context.Context, url string, event *RelayEvent) error {
+
+	if len(url) == 0 {
+		return errors.New("webhook url is required")
+	}
+
+	now := time.Now()
+	delivery := &RelayDelivery{
+		Id:        uuid.NewV4().String(),
+		Url:       url,
+		Event:     event,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return this.deliver(ctx, delivery, this.MaxAttempts)
+}
+
+// DeliverAsync run Deliver on background
+func (this *Relay) DeliverAsync(url string, event *RelayEvent) {
+	go func() {
+		if err := this.Deliver(context.Background(), url, event); err != nil && this.Debug {
+			fmt.Println("**** Webhook.Relay: ", err)
+		}
+	}()
+}
+
+// Replay deliver again a event from dead letter queue, on success it's removed from queue.
+// Replay don't wait the Deliver backoff, it makes ReplayAttempts attempts (default 1).
+// On failure the delivery stays on queue with the new attempts
+func (this *Relay) Replay(ctx context.Context, id string) error {
+
+	if this.DeadLetter == nil {
+		return errors.New("dead letter queue is required")
+	}
+
+	delivery, err := this.DeadLetter.Get(id)
+
+	if err != nil {
+		return err
+	}
+
+	if err := this.deliver(ctx, delivery, this.ReplayAttempts); err != nil {
+		return err
+	}
+
+	return this.DeadLetter.Remove(id)
+}
+
+// ReplayAll replay every delivery of dead letter queue, returns the errors by delivery id
+func (this *Relay) ReplayAll(ctx context.Context) (map[string]error, error) {
+
+	if this.DeadLetter == nil {
+		return nil, errors.New("dead letter queue is required")
+	}
+
+	deliveries, err := this.DeadLetter.List()
+
+	if err != nil {
+		return nil, err
+	}
+
+	errs := map[string]error{}
+
+	for _, delivery := range deliveries {
+		if err := this.Replay(ctx, delivery.Id); err != nil {
+			errs[delivery.Id] = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return errs, nil
+}
+
+// deliver make at most maxAttempts attempts, delivery.Attempts keep the total of attempts
+func (this *Relay) deliver(ctx context.Context, delivery *RelayDelivery, maxAttempts int) error {
+
+	payload, err := json.Marshal(delivery.Event)
+
+	if err != nil {
+		return err
+	}
+
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		delivery.Attempts++
+		delivery.UpdatedAt = time.Now()
+
+		statusCode, err := this.post(ctx, delivery, payload)
+
+		if err == nil {
+			return nil
+		}
+
+		delivery.LastStatusCode = statusCode
+		delivery.LastError = err.Error()
+
+		if this.Debug {
+			fmt.Printf("**** Webhook.Relay: delivery %v attempt %v error: %v\n", delivery.Id, delivery.Attempts, err)
+		}
+
+		if this.OnFailure != nil {
+			this.OnFailure(delivery)
+		}
+
+		if attempt >= maxAttempts || !isRelayRetryable(statusCode) {
+			return this.deadLetter(delivery, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return this.deadLetter(delivery, ctx.Err())
+		case <-time.After(this.backoff(attempt)):
+		}
+	}
+}
+
+func (this *Relay) deadLetter(delivery *RelayDelivery, err error) error {
+	if this.DeadLetter != nil {
+		if pushErr := this.DeadLetter.Push(delivery); pushErr != nil {
+			return fmt.Errorf("%v (dead letter error: %v)", err, pushErr)
+		}
+	}
+	return err
+}
+
+func (this *Relay) post(ctx context.Context, delivery *RelayDelivery, payload []byte) (int, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(payload))
+
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RelayEventIdHeader, delivery.Event.Id)
+	req.Header.Set(RelayTimestampHeader, timestamp)
+	req.Header.Set(RelaySignatureHeader, SignRelayPayload(this.Secret, timestamp, payload))
+
+	res, err := this.Client.Do(req)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook url replied status %v", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// isRelayRetryable client errors are permanent, the same request will fail again.
+// Timeout and rate limit are retried
+func isRelayRetryable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return statusCode < 400 || statusCode > 499
+}
+
+func (this *Relay) backoff(attempt int) time.Duration {
+	backoff := this.InitialBackoff
+	for i := 1; i < attempt && backoff < this.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > this.MaxBackoff {
+		return this.MaxBackoff
+	}
+	return backoff
+}
+
+// SignRelayPayload sign timestamp.payload with HMAC-SHA256, the result is sha256=<hex>
+func SignRelayPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRelaySignature is used by consumer api to check the relay request.
+// Use tolerance <= 0 to DefaultRelayTolerance
+func VerifyRelaySignature(secret string, timestamp string, signature string, payload []byte, tolerance time.Duration) error {
+
+	if tolerance <= 0 {
+		tolerance = DefaultRelayTolerance
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return api.NewWebhookAuthError(api.GatewayNone, "invalid timestamp")
+	}
+
+	age := time.Since(time.Unix(unix, 0))
+
+	if age > tolerance || age < -tolerance {
+		return api.NewWebhookAuthError(api.GatewayNone, "timestamp out of tolerance")
+	}
+
+	if !api.SecureCompare(SignRelayPayload(secret, timestamp, payload), signature) {
+		return api.NewWebhookAuthError(api.GatewayNone, "invalid signature")
+	}
+
+	return nil
+}
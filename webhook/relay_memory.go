@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryDeadLetterQueue keep failed deliveries in memory, they are lost when the
+// process stops. Use SQLDeadLetterQueue to keep them across restarts
+type MemoryDeadLetterQueue struct {
+	mutex      sync.Mutex
+	deliveries map[string]*RelayDelivery
+}
+
+func NewMemoryDeadLetterQueue() *MemoryDeadLetterQueue {
+	return &MemoryDeadLetterQueue{deliveries: map[string]*RelayDelivery{}}
+}
+
+func (this *MemoryDeadLetterQueue) Push(delivery *RelayDelivery) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.deliveries[delivery.Id] = delivery.copy()
+	return nil
+}
+
+func (this *MemoryDeadLetterQueue) Get(id string) (*RelayDelivery, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if delivery, ok := this.deliveries[id]; ok {
+		return delivery.copy(), nil
+	}
+	return nil, ErrDeliveryNotFound
+}
+
+// List returns copies of deliveries ordered by creation
+func (this *MemoryDeadLetterQueue) List() ([]*RelayDelivery, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	deliveries := make([]*RelayDelivery, 0, len(this.deliveries))
+	for _, delivery := range this.deliveries {
+		deliveries = append(deliveries, delivery.copy())
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	return deliveries, nil
+}
+
+func (this *MemoryDeadLetterQueue) Remove(id string) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	delete(this.deliveries, id)
+	return nil
+}
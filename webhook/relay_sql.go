@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultSQLDeadLetterTable = "webhook_dead_letter"
+)
+
+// SQLDeadLetterQueue keep failed deliveries on a SQL table, eg. SQLite with SQLDialectSQLite.
+// The database driver must be imported by the application. The event is saved as json and
+// times are unix nano.
+type SQLDeadLetterQueue struct {
+	DB      *sql.DB
+	Table   string
+	Dialect *SQLDialect
+}
+
+func NewSQLDeadLetterQueue(db *sql.DB, dialect *SQLDialect) *SQLDeadLetterQueue {
+	return &SQLDeadLetterQueue{DB: db, Table: DefaultSQLDeadLetterTable, Dialect: dialect}
+}
+
+func (this *SQLDeadLetterQueue) CreateTable() error {
+	_, err := this.DB.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id VARCHAR(64) PRIMARY KEY,
+		url TEXT NOT NULL,
+		event TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		last_error TEXT NOT NULL,
+		last_status_code INTEGER NOT NULL,
+		created_at BIGINT NOT NULL,
+		updated_at BIGINT NOT NULL)`, this.Table))
+	return err
+}
+
+func (this *SQLDeadLetterQueue) Push(delivery *RelayDelivery) error {
+
+	event, err := json.Marshal(delivery.Event)
+
+	if err != nil {
+		return err
+	}
+
+	// RowsAffected can't be used to detect a new delivery, MySQL returns zero when
+	// the update don't change any value
+	var count int
+
+	err = this.DB.QueryRow(this.query("SELECT COUNT(*) FROM %v WHERE id = {1}"), delivery.Id).Scan(&count)
+
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		_, err = this.DB.Exec(
+			this.query("UPDATE %v SET attempts = {1}, last_error = {2}, last_status_code = {3}, updated_at = {4} WHERE id = {5}"),
+			delivery.Attempts, delivery.LastError, delivery.LastStatusCode, delivery.UpdatedAt.UnixNano(), delivery.Id)
+		return err
+	}
+
+	_, err = this.DB.Exec(
+		this.query("INSERT INTO %v (id, url, event, attempts, last_error, last_status_code, created_at, updated_at) VALUES ({1}, {2}, {3}, {4}, {5}, {6}, {7}, {8})"),
+		delivery.Id, delivery.Url, string(event), delivery.Attempts, delivery.LastError, delivery.LastStatusCode,
+		delivery.CreatedAt.UnixNano(), delivery.UpdatedAt.UnixNano())
+
+	return err
+}
+
+func (this *SQLDeadLetterQueue) Get(id string) (*RelayDelivery, error) {
+
+	rows, err := this.DB.Query(this.query(this.selectQuery()+" WHERE id = {1}"), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := this.scan(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, ErrDeliveryNotFound
+	}
+
+	return deliveries[0], nil
+}
+
+// List returns deliveries ordered by creation
+func (this *SQLDeadLetterQueue) List() ([]*RelayDelivery, error) {
+
+	rows, err := this.DB.Query(this.query(this.selectQuery() + " ORDER BY created_at"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return this.scan(rows)
+}
+
+func (this *SQLDeadLetterQueue) Remove(id string) error {
+	_, err := this.DB.Exec(this.query("DELETE FROM %v WHERE id = {1}"), id)
+	return err
+}
+
+func (this *SQLDeadLetterQueue) selectQuery() string {
+	return "SELECT id, url, event, attempts, last_error, last_status_code, created_at, updated_at FROM %v"
+}
+
+func (this *SQLDeadLetterQueue) scan(rows *sql.Rows) ([]*RelayDelivery, error) {
+
+	defer rows.Close()
+
+	deliveries := []*RelayDelivery{}
+
+	for rows.Next() {
+
+		var event string
+		var createdAt, updatedAt int64
+		delivery := new(RelayDelivery)
+
+		err := rows.Scan(
+			&delivery.Id, &delivery.Url, &event, &delivery.Attempts, &delivery.LastError,
+			&delivery.LastStatusCode, &createdAt, &updatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(event), &delivery.Event); err != nil {
+			return nil, err
+		}
+
+		delivery.CreatedAt = time.Unix(0, createdAt)
+		delivery.UpdatedAt = time.Unix(0, updatedAt)
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// query set table name and replace {n} by dialect placeholder
+func (this *SQLDeadLetterQueue) query(query string) string {
+	return sqlQuery(this.Table, this.Dialect, query)
+}